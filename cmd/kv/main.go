@@ -3,9 +3,21 @@ package main
 import (
 	"os"
 
+	_ "github.com/bayhaqi/kv/pkg/cmd/alias"
+	_ "github.com/bayhaqi/kv/pkg/cmd/config"
+	_ "github.com/bayhaqi/kv/pkg/cmd/delete"
+	_ "github.com/bayhaqi/kv/pkg/cmd/deleted"
+	_ "github.com/bayhaqi/kv/pkg/cmd/diff"
 	_ "github.com/bayhaqi/kv/pkg/cmd/edit"
+	_ "github.com/bayhaqi/kv/pkg/cmd/get"
+	_ "github.com/bayhaqi/kv/pkg/cmd/list"
+	_ "github.com/bayhaqi/kv/pkg/cmd/purge"
+	_ "github.com/bayhaqi/kv/pkg/cmd/recover"
+	_ "github.com/bayhaqi/kv/pkg/cmd/rollback"
 	"github.com/bayhaqi/kv/pkg/cmd/root"
+	_ "github.com/bayhaqi/kv/pkg/cmd/set"
 	_ "github.com/bayhaqi/kv/pkg/cmd/show"
+	_ "github.com/bayhaqi/kv/pkg/cmd/version"
 )
 
 func main() {
@@ -0,0 +1,218 @@
+package difftui
+
+import (
+	"fmt"
+
+	"github.com/bayhaqi/kv/internal/textdiff"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	baseBoxStyle = boxStyle.Copy().
+			BorderForeground(lipgloss.Color("#6B7280"))
+
+	theirsBoxStyle = boxStyle.Copy().
+			BorderForeground(lipgloss.Color("#FBBF24"))
+
+	baseTitleStyle = titleStyle.Copy().
+			Foreground(lipgloss.Color("#9CA3AF"))
+
+	theirsTitleStyle = titleStyle.Copy().
+				Foreground(lipgloss.Color("#FBBF24"))
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FBBF24")).
+			Bold(true).
+			Padding(0, 1)
+)
+
+// Resolution is how the user chose to resolve a conflicting edit
+type Resolution int
+
+const (
+	// ResolutionAbort discards the user's edit
+	ResolutionAbort Resolution = iota
+	// ResolutionOverwrite writes the user's edit over the newer version
+	ResolutionOverwrite
+)
+
+// ConflictModel shows a three-way view of an edit that raced with another
+// update: the version the edit started from, the version that was written
+// meanwhile, and the user's edit. The last two are marked up against the base.
+type ConflictModel struct {
+	base       string
+	theirs     string
+	mine       string
+	secretName string
+	baseTitle  string
+	theirTitle string
+	viewports  [3]viewport.Model
+	ready      bool
+	width      int
+	height     int
+	resolution Resolution
+}
+
+// NewConflictModel creates a new conflict TUI model
+func NewConflictModel(base, theirs, mine, secretName, baseVersion, theirVersion string) ConflictModel {
+	return ConflictModel{
+		base:       base,
+		theirs:     theirs,
+		mine:       mine,
+		secretName: secretName,
+		baseTitle:  fmt.Sprintf("Base (%s)", baseVersion),
+		theirTitle: fmt.Sprintf("Theirs (%s)", theirVersion),
+	}
+}
+
+// Init initializes the model
+func (m ConflictModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages and updates the model
+func (m ConflictModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q", "a", "A", "n", "N", "ctrl+c":
+			m.resolution = ResolutionAbort
+			return m, tea.Quit
+		case "o", "O":
+			m.resolution = ResolutionOverwrite
+			return m, tea.Quit
+		case "up", "k", "down", "j", "pgup", "ctrl+b", "pgdown", "ctrl+f":
+			var cmd tea.Cmd
+			m.viewports[0], cmd = m.viewports[0].Update(msg)
+			m.viewports[1].SetYOffset(m.viewports[0].YOffset)
+			m.viewports[2].SetYOffset(m.viewports[0].YOffset)
+			return m, cmd
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+		headerHeight := 3 // Title
+		footerHeight := 4 // Warning and help text
+
+		viewportWidth := (msg.Width / 3) - 4
+		viewportHeight := msg.Height - headerHeight - footerHeight
+
+		for i := range m.viewports {
+			if !m.ready {
+				m.viewports[i] = viewport.New(viewportWidth, viewportHeight)
+			} else {
+				m.viewports[i].Width = viewportWidth
+				m.viewports[i].Height = viewportHeight
+			}
+		}
+		m.ready = true
+		m.updateViewportContent()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// updateViewportContent renders the base and both sides' changes against it
+func (m *ConflictModel) updateViewportContent() {
+	maxWidth := max(m.viewports[0].Width-10, 1)
+	columns := alignConflict(textdiff.Lines(m.base), textdiff.Lines(m.theirs), textdiff.Lines(m.mine))
+
+	// Every column gets the same number of rows per line so they stay
+	// aligned when only one of them wraps
+	rows := make([]int, len(columns[0]))
+	for n := range rows {
+		for _, lines := range columns {
+			rows[n] = max(rows[n], len(wrapLine(lines[n].content, maxWidth)))
+		}
+	}
+	for i, lines := range columns {
+		m.viewports[i].SetContent(renderDiffLines(lines, rows, maxWidth, false))
+	}
+}
+
+// alignConflict lays out the base, theirs and mine as three columns of
+// equal length. Lines inserted on either side get gap rows in the other two
+// columns, so each base line is on the same row everywhere.
+func alignConflict(baseLines, theirLines, myLines []string) [3][]diffLine {
+	theirsInserted, theirsPaired := splitByBase(computeDiff(baseLines, theirLines))
+	mineInserted, minePaired := splitByBase(computeDiff(baseLines, myLines))
+	gap := diffLine{kind: lineGap}
+
+	var columns [3][]diffLine
+	for k := 0; k <= len(baseLines); k++ {
+		pad := max(len(theirsInserted[k]), len(mineInserted[k]))
+		for i, inserted := range [][]diffLine{nil, theirsInserted[k], mineInserted[k]} {
+			columns[i] = append(columns[i], inserted...)
+			for range pad - len(inserted) {
+				columns[i] = append(columns[i], gap)
+			}
+		}
+
+		if k < len(baseLines) {
+			columns[0] = append(columns[0], diffLine{lineNum: k + 1, content: baseLines[k], kind: lineUnchanged})
+			columns[1] = append(columns[1], theirsPaired[k])
+			columns[2] = append(columns[2], minePaired[k])
+		}
+	}
+	return columns
+}
+
+// splitByBase takes the two sides of a diff against the base and returns
+// the new lines inserted before each base line (the last entry holds the
+// ones after the final base line) and the new line paired with each base
+// line, which is a gap for a removed line
+func splitByBase(left, right []diffLine) ([][]diffLine, []diffLine) {
+	var inserted [][]diffLine
+	var paired []diffLine
+	var pending []diffLine
+
+	for i := range left {
+		if left[i].kind == lineGap {
+			pending = append(pending, right[i])
+			continue
+		}
+		inserted = append(inserted, pending)
+		paired = append(paired, right[i])
+		pending = nil
+	}
+	return append(inserted, pending), paired
+}
+
+// View renders the TUI
+func (m ConflictModel) View() string {
+	if !m.ready {
+		return "\n  Initializing..."
+	}
+
+	boxWidth := (m.width / 3) - 2
+	boxHeight := m.height - 7
+
+	columns := []string{
+		lipgloss.JoinVertical(lipgloss.Left,
+			baseTitleStyle.Render(m.baseTitle),
+			baseBoxStyle.Width(boxWidth).Height(boxHeight).Render(m.viewports[0].View())),
+		lipgloss.JoinVertical(lipgloss.Left,
+			theirsTitleStyle.Render(m.theirTitle),
+			theirsBoxStyle.Width(boxWidth).Height(boxHeight).Render(m.viewports[1].View())),
+		lipgloss.JoinVertical(lipgloss.Left,
+			rightTitleStyle.Render("Mine (edited)"),
+			rightBoxStyle.Width(boxWidth).Height(boxHeight).Render(m.viewports[2].View())),
+	}
+	content := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+
+	warning := warningStyle.Render(
+		fmt.Sprintf("'%s' was updated while you were editing it", m.secretName),
+	)
+	help := footerStyle.Render("↑↓ Scroll • O Overwrite with mine • A/ESC Abort")
+
+	return fmt.Sprintf("%s\n%s\n%s", content, warning, help)
+}
+
+// Resolution returns how the user chose to resolve the conflict
+func (m ConflictModel) Resolution() Resolution {
+	return m.resolution
+}
@@ -0,0 +1,86 @@
+package difftui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/internal/textdiff"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAlignConflict(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, theirs, mine string
+		want               [3][]string
+	}{
+		{
+			name: "unchanged",
+			base: "a\nb", theirs: "a\nb", mine: "a\nb",
+			want: [3][]string{
+				{" 1:a", " 2:b"},
+				{" 1:a", " 2:b"},
+				{" 1:a", " 2:b"},
+			},
+		},
+		{
+			name: "inserts at different ends",
+			base: "a\nb\nc", theirs: "x\na\nb\nc", mine: "a\nb\nc\ny",
+			want: [3][]string{
+				{"", " 1:a", " 2:b", " 3:c", ""},
+				{"+1:x", " 2:a", " 3:b", " 4:c", ""},
+				{"", " 1:a", " 2:b", " 3:c", "+4:y"},
+			},
+		},
+		{
+			name: "inserts of different length at the same place",
+			base: "a\nb", theirs: "a\nt1\nb", mine: "a\nm1\nm2\nb",
+			want: [3][]string{
+				{" 1:a", "", "", " 2:b"},
+				{" 1:a", "+2:t1", "", " 3:b"},
+				{" 1:a", "+2:m1", "+3:m2", " 4:b"},
+			},
+		},
+		{
+			name: "removed on one side, modified on the other",
+			base: "a\nb\nc", theirs: "a\nc", mine: "a\nB\nc",
+			want: [3][]string{
+				{" 1:a", " 2:b", " 3:c"},
+				{" 1:a", "", " 2:c"},
+				{" 1:a", "~2:B", " 3:c"},
+			},
+		},
+		{
+			name: "empty base",
+			base: "", theirs: "t", mine: "m1\nm2",
+			want: [3][]string{
+				{"", ""},
+				{"+1:t", ""},
+				{"+1:m1", "+2:m2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := alignConflict(textdiff.Lines(tt.base), textdiff.Lines(tt.theirs), textdiff.Lines(tt.mine))
+			for i, name := range []string{"base", "theirs", "mine"} {
+				if got := sideRows(columns[i]); !reflect.DeepEqual(got, tt.want[i]) {
+					t.Errorf("%s = %q, want %q", name, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConflictModelNarrowWindow(t *testing.T) {
+	long := strings.Repeat("x", 50)
+	m := NewConflictModel("a\n"+long, "b\n"+long, "c\n"+long, "db", "aaaaaaaa", "bbbbbbbb")
+
+	// The viewports are narrower than their padding; wrapping must still
+	// terminate
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 12, Height: 20})
+	if view := updated.(ConflictModel).View(); view == "" {
+		t.Error("empty view")
+	}
+}
@@ -3,7 +3,10 @@ package difftui
 import (
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/bayhaqi/kv/internal/textdiff"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -50,20 +53,51 @@ var (
 
 	unchangedLineStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#F3F4F6"))
+
+	// Emphasis for the changed parts within a modified line
+	removedEmphasisStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("#8B1E1E")).
+				Foreground(lipgloss.Color("#FFE3E3")).
+				Bold(true)
+
+	addedEmphasisStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("#1E6B2A")).
+				Foreground(lipgloss.Color("#E3FFE8")).
+				Bold(true)
+)
+
+// lineKind classifies a line on one side of the diff
+type lineKind int
+
+const (
+	lineUnchanged lineKind = iota
+	lineRemoved
+	lineAdded
+	lineModified
+	// lineGap is a placeholder aligned with a line that only exists on the other side
+	lineGap
 )
 
 type diffLine struct {
 	lineNum int
 	content string
-	isDiff  bool
-	isAdded bool
+	kind    lineKind
+	// highlights are the byte ranges of content that changed within a modified line
+	highlights [][2]int
 }
 
+// CloseMsg is sent when the user leaves a comparison opened with NewCompareModel
+type CloseMsg struct{}
+
 // Model represents the diff TUI model
 type Model struct {
 	oldValue      string
 	newValue      string
 	secretName    string
+	oldTitle      string
+	newTitle      string
+	compareOnly   bool
+	editable      bool
 	leftViewport  viewport.Model
 	rightViewport viewport.Model
 	ready         bool
@@ -71,6 +105,7 @@ type Model struct {
 	height        int
 	confirmed     bool
 	cancelled     bool
+	editRequested bool
 }
 
 // NewModel creates a new diff TUI model
@@ -79,6 +114,30 @@ func NewModel(oldValue, newValue, secretName string) Model {
 		oldValue:   oldValue,
 		newValue:   newValue,
 		secretName: secretName,
+		oldTitle:   "Previous Version",
+		newTitle:   "New Version",
+	}
+}
+
+// NewEditModel creates a diff TUI model for reviewing an edit. Besides
+// confirming or cancelling, the user can ask to go back to the editor.
+func NewEditModel(oldValue, newValue, secretName string) Model {
+	m := NewModel(oldValue, newValue, secretName)
+	m.editable = true
+	return m
+}
+
+// NewCompareModel creates a read-only diff of two versions with custom
+// titles. There is nothing to confirm: leaving it sends CloseMsg instead of
+// quitting, so it can be embedded in another TUI.
+func NewCompareModel(oldValue, newValue, secretName, oldTitle, newTitle string) Model {
+	return Model{
+		oldValue:    oldValue,
+		newValue:    newValue,
+		secretName:  secretName,
+		oldTitle:    oldTitle,
+		newTitle:    newTitle,
+		compareOnly: true,
 	}
 }
 
@@ -91,6 +150,17 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.compareOnly {
+			switch msg.String() {
+			case "esc", "q":
+				return m, func() tea.Msg { return CloseMsg{} }
+			case "ctrl+c":
+				return m, tea.Quit
+			case "y", "Y", "enter", "n", "N":
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "esc", "q", "n", "N":
 			m.cancelled = true
@@ -98,6 +168,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "y", "Y", "enter":
 			m.confirmed = true
 			return m, tea.Quit
+		case "e", "E":
+			if m.editable {
+				m.editRequested = true
+				return m, tea.Quit
+			}
 		case "up", "k":
 			var cmd tea.Cmd
 			m.leftViewport, cmd = m.leftViewport.Update(msg)
@@ -149,106 +224,203 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // updateViewportContent updates both viewports with content
 func (m *Model) updateViewportContent() {
-	maxWidth := m.leftViewport.Width - 10
+	maxWidth := max(m.leftViewport.Width-10, 1)
 
-	oldLines := strings.Split(m.oldValue, "\n")
-	newLines := strings.Split(m.newValue, "\n")
+	oldLines := textdiff.Lines(m.oldValue)
+	newLines := textdiff.Lines(m.newValue)
 
 	leftDiff, rightDiff := computeDiff(oldLines, newLines)
 
-	oldContent := renderDiffLines(leftDiff, maxWidth, true)
-	newContent := renderDiffLines(rightDiff, maxWidth, false)
+	// Both sides get the same number of rows per line so they stay aligned
+	// when only one side wraps
+	rows := make([]int, len(leftDiff))
+	for i := range leftDiff {
+		rows[i] = max(len(wrapLine(leftDiff[i].content, maxWidth)), len(wrapLine(rightDiff[i].content, maxWidth)))
+	}
+
+	oldContent := renderDiffLines(leftDiff, rows, maxWidth, true)
+	newContent := renderDiffLines(rightDiff, rows, maxWidth, false)
 
 	m.leftViewport.SetContent(oldContent)
 	m.rightViewport.SetContent(newContent)
 }
 
-// computeDiff compares two sets of lines and marks differences
+// computeDiff aligns two sets of lines using a sequence diff. Removed and
+// added lines get a gap on the other side; a removal directly followed by
+// an addition is paired up line by line as modifications.
 func computeDiff(oldLines, newLines []string) ([]diffLine, []diffLine) {
 	var leftDiff, rightDiff []diffLine
+	gap := diffLine{kind: lineGap}
+
+	ops := textdiff.Diff(oldLines, newLines)
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+
+		switch op.Kind {
+		case textdiff.Equal:
+			for k := 0; k < op.AEnd-op.AStart; k++ {
+				leftDiff = append(leftDiff, diffLine{lineNum: op.AStart + k + 1, content: oldLines[op.AStart+k], kind: lineUnchanged})
+				rightDiff = append(rightDiff, diffLine{lineNum: op.BStart + k + 1, content: newLines[op.BStart+k], kind: lineUnchanged})
+			}
+
+		case textdiff.Delete:
+			removed := op
+			added := textdiff.Op{AStart: op.AEnd, AEnd: op.AEnd, BStart: op.BEnd, BEnd: op.BEnd}
+			if i+1 < len(ops) && ops[i+1].Kind == textdiff.Insert {
+				added = ops[i+1]
+				i++
+			}
+
+			nRemoved := removed.AEnd - removed.AStart
+			nAdded := added.BEnd - added.BStart
+			for k := 0; k < nRemoved || k < nAdded; k++ {
+				switch {
+				case k < nRemoved && k < nAdded:
+					oldLine, newLine := oldLines[removed.AStart+k], newLines[added.BStart+k]
+					oldHighlights, newHighlights := inlineChanges(oldLine, newLine)
+					leftDiff = append(leftDiff, diffLine{lineNum: removed.AStart + k + 1, content: oldLine, kind: lineModified, highlights: oldHighlights})
+					rightDiff = append(rightDiff, diffLine{lineNum: added.BStart + k + 1, content: newLine, kind: lineModified, highlights: newHighlights})
+				case k < nRemoved:
+					leftDiff = append(leftDiff, diffLine{lineNum: removed.AStart + k + 1, content: oldLines[removed.AStart+k], kind: lineRemoved})
+					rightDiff = append(rightDiff, gap)
+				default:
+					leftDiff = append(leftDiff, gap)
+					rightDiff = append(rightDiff, diffLine{lineNum: added.BStart + k + 1, content: newLines[added.BStart+k], kind: lineAdded})
+				}
+			}
 
-	maxLen := len(oldLines)
-	if len(newLines) > maxLen {
-		maxLen = len(newLines)
+		case textdiff.Insert:
+			for k := 0; k < op.BEnd-op.BStart; k++ {
+				leftDiff = append(leftDiff, gap)
+				rightDiff = append(rightDiff, diffLine{lineNum: op.BStart + k + 1, content: newLines[op.BStart+k], kind: lineAdded})
+			}
+		}
 	}
 
-	for i := 0; i < maxLen; i++ {
-		oldLine := ""
-		newLine := ""
+	return leftDiff, rightDiff
+}
 
-		if i < len(oldLines) {
-			oldLine = oldLines[i]
+// inlineChanges finds the changed parts of a modified line by diffing its
+// tokens, returning byte ranges to highlight in the old and new line
+func inlineChanges(oldLine, newLine string) ([][2]int, [][2]int) {
+	oldTokens, oldStarts := tokenize(oldLine)
+	newTokens, newStarts := tokenize(newLine)
+
+	var oldRanges, newRanges [][2]int
+	for _, op := range textdiff.Diff(oldTokens, newTokens) {
+		switch op.Kind {
+		case textdiff.Delete:
+			oldRanges = appendRange(oldRanges, oldStarts[op.AStart], oldStarts[op.AEnd])
+		case textdiff.Insert:
+			newRanges = appendRange(newRanges, newStarts[op.BStart], newStarts[op.BEnd])
 		}
-		if i < len(newLines) {
-			newLine = newLines[i]
+	}
+	return oldRanges, newRanges
+}
+
+// tokenize splits a line into runs of letters and digits and single other
+// characters, so "Password=abc;" diffs as "Password", "=", "abc", ";".
+// starts holds each token's byte offset plus a final entry for len(line).
+func tokenize(line string) ([]string, []int) {
+	var tokens []string
+	var starts []int
+
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		end := i + size
+		if isWordRune(r) {
+			for end < len(line) {
+				next, nextSize := utf8.DecodeRuneInString(line[end:])
+				if !isWordRune(next) {
+					break
+				}
+				end += nextSize
+			}
 		}
+		tokens = append(tokens, line[i:end])
+		starts = append(starts, i)
+		i = end
+	}
 
-		isDifferent := oldLine != newLine
+	return tokens, append(starts, len(line))
+}
 
-		// Left side (old)
-		if i < len(oldLines) {
-			leftDiff = append(leftDiff, diffLine{
-				lineNum: i + 1,
-				content: oldLine,
-				isDiff:  isDifferent, // Mark as diff if line is different or removed
-				isAdded: false,
-			})
-		} else {
-			// Line doesn't exist in old (was added in new)
-			leftDiff = append(leftDiff, diffLine{
-				lineNum: 0,
-				content: "",
-				isDiff:  false,
-				isAdded: false,
-			})
-		}
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
 
-		// Right side (new)
-		if i < len(newLines) {
-			rightDiff = append(rightDiff, diffLine{
-				lineNum: i + 1,
-				content: newLine,
-				isDiff:  isDifferent && oldLine != "",
-				isAdded: oldLine == "",
-			})
-		} else {
-			// Line doesn't exist in new (was removed)
-			rightDiff = append(rightDiff, diffLine{
-				lineNum: 0,
-				content: "",
-				isDiff:  false,
-				isAdded: false,
-			})
+// appendRange adds a byte range, merging it with the previous one if they touch
+func appendRange(ranges [][2]int, start, end int) [][2]int {
+	if start >= end {
+		return ranges
+	}
+	if n := len(ranges); n > 0 && ranges[n-1][1] == start {
+		ranges[n-1][1] = end
+		return ranges
+	}
+	return append(ranges, [2]int{start, end})
+}
+
+// renderHighlighted styles the part of a line in [start, end), emphasizing
+// the bytes covered by highlights
+func renderHighlighted(line string, start, end int, highlights [][2]int, base, emphasis lipgloss.Style) string {
+	var result strings.Builder
+
+	pos := start
+	for _, h := range highlights {
+		hStart, hEnd := max(h[0], start), min(h[1], end)
+		if hStart >= hEnd {
+			continue
 		}
+		if pos < hStart {
+			result.WriteString(base.Render(line[pos:hStart]))
+		}
+		result.WriteString(emphasis.Render(line[hStart:hEnd]))
+		pos = hEnd
+	}
+	if pos < end {
+		result.WriteString(base.Render(line[pos:end]))
 	}
 
-	return leftDiff, rightDiff
+	return result.String()
 }
 
-// renderDiffLines renders diff lines with appropriate styling
-func renderDiffLines(lines []diffLine, width int, isLeft bool) string {
+// renderDiffLines renders diff lines with appropriate styling, padding
+// line n to rows[n] rows
+func renderDiffLines(lines []diffLine, rows []int, width int, isLeft bool) string {
 	var result strings.Builder
 
-	for _, line := range lines {
-		if line.lineNum == 0 {
+	for n, line := range lines {
+		if line.kind == lineGap {
 			// Empty line placeholder
-			result.WriteString(lineNumStyle.Render("    "))
-			result.WriteString(" │ \n")
+			for i := 0; i < rows[n]; i++ {
+				result.WriteString(lineNumStyle.Render("    "))
+				result.WriteString(" │ \n")
+			}
 			continue
 		}
 
-		wrappedLines := wrapLine(line.content, width)
-		for i, wrappedContent := range wrappedLines {
+		wrappedRanges := wrapLineRanges(line.content, width)
+		for len(wrappedRanges) < rows[n] {
+			wrappedRanges = append(wrappedRanges, [2]int{len(line.content), len(line.content)})
+		}
+		for i, r := range wrappedRanges {
+			wrappedContent := line.content[r[0]:r[1]]
 			// Line number only on first wrapped line
 			if i == 0 {
 				result.WriteString(lineNumStyle.Render(fmt.Sprintf("%d", line.lineNum)))
-				if isLeft && line.isDiff {
+				switch line.kind {
+				case lineRemoved:
 					result.WriteString(removedLineStyle.Render(" - "))
-				} else if !isLeft && line.isAdded {
+				case lineAdded:
 					result.WriteString(addedLineStyle.Render(" + "))
-				} else if !isLeft && line.isDiff {
-					result.WriteString(addedLineStyle.Render(" ~ "))
-				} else {
+				case lineModified:
+					if isLeft {
+						result.WriteString(removedLineStyle.Render(" ~ "))
+					} else {
+						result.WriteString(addedLineStyle.Render(" ~ "))
+					}
+				default:
 					result.WriteString(" │ ")
 				}
 			} else {
@@ -258,12 +430,17 @@ func renderDiffLines(lines []diffLine, width int, isLeft bool) string {
 			}
 
 			// Apply styling to content
-			if line.isDiff && isLeft {
+			switch {
+			case line.kind == lineUnchanged:
+				result.WriteString(unchangedLineStyle.Render(wrappedContent))
+			case line.kind == lineModified && isLeft:
+				result.WriteString(renderHighlighted(line.content, r[0], r[1], line.highlights, removedLineStyle, removedEmphasisStyle))
+			case line.kind == lineModified:
+				result.WriteString(renderHighlighted(line.content, r[0], r[1], line.highlights, addedLineStyle, addedEmphasisStyle))
+			case isLeft:
 				result.WriteString(removedLineStyle.Render(wrappedContent))
-			} else if (line.isDiff || line.isAdded) && !isLeft {
+			default:
 				result.WriteString(addedLineStyle.Render(wrappedContent))
-			} else {
-				result.WriteString(unchangedLineStyle.Render(wrappedContent))
 			}
 
 			result.WriteString("\n")
@@ -275,16 +452,28 @@ func renderDiffLines(lines []diffLine, width int, isLeft bool) string {
 
 // wrapLine wraps a single line to the specified width
 func wrapLine(line string, width int) []string {
+	ranges := wrapLineRanges(line, width)
+	wrapped := make([]string, len(ranges))
+	for i, r := range ranges {
+		wrapped[i] = line[r[0]:r[1]]
+	}
+	return wrapped
+}
+
+// wrapLineRanges wraps a single line to the specified width, returning the
+// byte range of each wrapped row so styling can follow the original offsets
+func wrapLineRanges(line string, width int) [][2]int {
 	if len(line) <= width {
-		return []string{line}
+		return [][2]int{{0, len(line)}}
 	}
 
-	var wrapped []string
+	var wrapped [][2]int
+	offset := 0
 	remaining := line
 
 	for len(remaining) > 0 {
 		if len(remaining) <= width {
-			wrapped = append(wrapped, remaining)
+			wrapped = append(wrapped, [2]int{offset, offset + len(remaining)})
 			break
 		}
 
@@ -300,8 +489,10 @@ func wrapLine(line string, width int) []string {
 			breakPoint = width
 		}
 
-		wrapped = append(wrapped, remaining[:breakPoint])
-		remaining = strings.TrimLeft(remaining[breakPoint:], " ")
+		wrapped = append(wrapped, [2]int{offset, offset + breakPoint})
+		rest := strings.TrimLeft(remaining[breakPoint:], " ")
+		offset += len(remaining) - len(rest)
+		remaining = rest
 	}
 
 	return wrapped
@@ -318,14 +509,14 @@ func (m Model) View() string {
 	boxHeight := m.height - 6
 
 	// Left side (old version)
-	leftTitle := leftTitleStyle.Render("Previous Version")
+	leftTitle := leftTitleStyle.Render(m.oldTitle)
 	leftBox := leftBoxStyle.
 		Width(boxWidth).
 		Height(boxHeight).
 		Render(m.leftViewport.View())
 
 	// Right side (new version)
-	rightTitle := rightTitleStyle.Render("New Version")
+	rightTitle := rightTitleStyle.Render(m.newTitle)
 	rightBox := rightBoxStyle.
 		Width(boxWidth).
 		Height(boxHeight).
@@ -341,6 +532,12 @@ func (m Model) View() string {
 		fmt.Sprintf("Secret: %s", m.secretName),
 	)
 	help := footerStyle.Render("↑↓ Scroll • Y/Enter Confirm • N/ESC Cancel")
+	if m.editable {
+		help = footerStyle.Render("↑↓ Scroll • Y/Enter Confirm • E Edit again • N/ESC Cancel")
+	}
+	if m.compareOnly {
+		help = footerStyle.Render("↑↓ Scroll • ESC/Q Back")
+	}
 
 	return fmt.Sprintf("%s\n%s\n%s", content, footer, help)
 }
@@ -354,3 +551,8 @@ func (m Model) Confirmed() bool {
 func (m Model) Cancelled() bool {
 	return m.cancelled
 }
+
+// EditRequested returns whether the user asked to go back to the editor
+func (m Model) EditRequested() bool {
+	return m.editRequested
+}
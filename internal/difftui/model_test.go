@@ -0,0 +1,213 @@
+package difftui
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/bayhaqi/kv/internal/textdiff"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sideRows renders one side of a diff compactly: the kind, line number and
+// content of each row, or "" for a gap
+func sideRows(lines []diffLine) []string {
+	marks := map[lineKind]string{lineUnchanged: " ", lineRemoved: "-", lineAdded: "+", lineModified: "~"}
+	rows := make([]string, len(lines))
+	for i, line := range lines {
+		if line.kind != lineGap {
+			rows[i] = fmt.Sprintf("%s%d:%s", marks[line.kind], line.lineNum, line.content)
+		}
+	}
+	return rows
+}
+
+func TestComputeDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		old, new  string
+		wantLeft  []string
+		wantRight []string
+	}{
+		{
+			name: "identical",
+			old:  "a\nb", new: "a\nb",
+			wantLeft:  []string{" 1:a", " 2:b"},
+			wantRight: []string{" 1:a", " 2:b"},
+		},
+		{
+			name: "insert at top keeps the rest aligned",
+			old:  "a\nb\nc", new: "x\na\nb\nc",
+			wantLeft:  []string{"", " 1:a", " 2:b", " 3:c"},
+			wantRight: []string{"+1:x", " 2:a", " 3:b", " 4:c"},
+		},
+		{
+			name: "delete in middle",
+			old:  "a\nb\nc", new: "a\nc",
+			wantLeft:  []string{" 1:a", "-2:b", " 3:c"},
+			wantRight: []string{" 1:a", "", " 2:c"},
+		},
+		{
+			name: "modified line",
+			old:  "a\nb\nc", new: "a\nB\nc",
+			wantLeft:  []string{" 1:a", "~2:b", " 3:c"},
+			wantRight: []string{" 1:a", "~2:B", " 3:c"},
+		},
+		{
+			name: "one line replaced by two",
+			old:  "a\nb\nc", new: "a\nx\ny\nc",
+			wantLeft:  []string{" 1:a", "~2:b", "", " 3:c"},
+			wantRight: []string{" 1:a", "~2:x", "+3:y", " 4:c"},
+		},
+		{
+			name: "two lines replaced by one",
+			old:  "a\nx\ny\nc", new: "a\nb\nc",
+			wantLeft:  []string{" 1:a", "~2:x", "-3:y", " 4:c"},
+			wantRight: []string{" 1:a", "~2:b", "", " 3:c"},
+		},
+		{
+			name: "from empty",
+			old:  "", new: "a\nb",
+			wantLeft:  []string{"", ""},
+			wantRight: []string{"+1:a", "+2:b"},
+		},
+		{
+			name: "to empty",
+			old:  "a", new: "",
+			wantLeft:  []string{"-1:a"},
+			wantRight: []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, right := computeDiff(textdiff.Lines(tt.old), textdiff.Lines(tt.new))
+			if got := sideRows(left); !reflect.DeepEqual(got, tt.wantLeft) {
+				t.Errorf("left = %q, want %q", got, tt.wantLeft)
+			}
+			if got := sideRows(right); !reflect.DeepEqual(got, tt.wantRight) {
+				t.Errorf("right = %q, want %q", got, tt.wantRight)
+			}
+		})
+	}
+}
+
+// highlighted returns the parts of line covered by ranges
+func highlighted(line string, ranges [][2]int) []string {
+	var parts []string
+	for _, r := range ranges {
+		parts = append(parts, line[r[0]:r[1]])
+	}
+	return parts
+}
+
+func TestInlineChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		wantOld  []string
+		wantNew  []string
+	}{
+		{
+			name:    "only the password token",
+			old:     "Server=x;Password=abc;",
+			new:     "Server=x;Password=xyz;",
+			wantOld: []string{"abc"},
+			wantNew: []string{"xyz"},
+		},
+		{
+			name:    "added token",
+			old:     "a=1;",
+			new:     "a=1;b=2;",
+			wantOld: nil,
+			wantNew: []string{"b=2;"},
+		},
+		{
+			name:    "two separate changes",
+			old:     "user=alice pass=one",
+			new:     "user=bob pass=two",
+			wantOld: []string{"alice", "one"},
+			wantNew: []string{"bob", "two"},
+		},
+		{
+			name:    "non-ASCII word",
+			old:     "Passwort=grüße;Host=x",
+			new:     "Passwort=grüßen;Host=x",
+			wantOld: []string{"grüße"},
+			wantNew: []string{"grüßen"},
+		},
+		{
+			name:    "non-ASCII punctuation",
+			old:     "名前=値→古い",
+			new:     "名前=値→新しい",
+			wantOld: []string{"古い"},
+			wantNew: []string{"新しい"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldRanges, newRanges := inlineChanges(tt.old, tt.new)
+			if got := highlighted(tt.old, oldRanges); !reflect.DeepEqual(got, tt.wantOld) {
+				t.Errorf("old highlights = %q, want %q", got, tt.wantOld)
+			}
+			if got := highlighted(tt.new, newRanges); !reflect.DeepEqual(got, tt.wantNew) {
+				t.Errorf("new highlights = %q, want %q", got, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, starts := tokenize("Pass_1=ü;")
+	if want := []string{"Pass_1", "=", "ü", ";"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("tokens = %q, want %q", tokens, want)
+	}
+	if want := []int{0, 6, 7, 9, 10}; !reflect.DeepEqual(starts, want) {
+		t.Errorf("starts = %v, want %v", starts, want)
+	}
+}
+
+func TestComputeDiffHighlightsModifiedLines(t *testing.T) {
+	left, right := computeDiff(
+		textdiff.Lines("host=db\nServer=x;Password=abc;"),
+		textdiff.Lines("host=db\nServer=x;Password=xyz;"))
+	if left[0].highlights != nil || right[0].highlights != nil {
+		t.Errorf("unchanged line highlighted: %v, %v", left[0].highlights, right[0].highlights)
+	}
+	if got := highlighted(left[1].content, left[1].highlights); !reflect.DeepEqual(got, []string{"abc"}) {
+		t.Errorf("left highlights = %q", got)
+	}
+	if got := highlighted(right[1].content, right[1].highlights); !reflect.DeepEqual(got, []string{"xyz"}) {
+		t.Errorf("right highlights = %q", got)
+	}
+}
+
+func TestEditKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    Model
+		wantEdit bool
+	}{
+		{"edit review", NewEditModel("old", "new", "db"), true},
+		{"plain review", NewModel("old", "new", "db"), false},
+		{"comparison", NewCompareModel("old", "new", "db", "a", "b"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, cmd := tt.model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+			m := updated.(Model)
+			if m.EditRequested() != tt.wantEdit {
+				t.Errorf("EditRequested() = %t, want %t", m.EditRequested(), tt.wantEdit)
+			}
+			if m.Confirmed() {
+				t.Error("e confirmed the change")
+			}
+			quit := false
+			if cmd != nil {
+				_, quit = cmd().(tea.QuitMsg)
+			}
+			if quit != tt.wantEdit {
+				t.Errorf("quit = %t, want %t", quit, tt.wantEdit)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package listtui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	// Styles
+	appStyle = lipgloss.NewStyle().
+			Padding(1, 2)
+
+	titleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(lipgloss.Color("#7D56F4")).
+			Padding(0, 1)
+)
+
+// Action is what the user chose to do with the selected secret
+type Action int
+
+const (
+	// ActionNone means the user quit without choosing a secret
+	ActionNone Action = iota
+	// ActionBrowse opens the version browser for the selected secret
+	ActionBrowse
+	// ActionEdit opens the edit flow for the selected secret
+	ActionEdit
+)
+
+type keyMap struct {
+	browse key.Binding
+	edit   key.Binding
+}
+
+var keys = keyMap{
+	browse: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "versions"),
+	),
+	edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit"),
+	),
+}
+
+// item adapts a secret to the bubbles list
+type item struct {
+	secret keyvault.SecretProperties
+}
+
+func (i item) Title() string { return i.secret.Name }
+
+func (i item) Description() string {
+	status := "enabled"
+	if !i.secret.Enabled {
+		status = "disabled"
+	}
+	parts := []string{status}
+
+	if i.secret.ContentType != "" {
+		parts = append(parts, i.secret.ContentType)
+	}
+	if i.secret.UpdatedOn != nil {
+		parts = append(parts, "updated "+i.secret.UpdatedOn.Local().Format("2006-01-02 15:04"))
+	}
+	if i.secret.ExpiresOn != nil {
+		parts = append(parts, "expires "+i.secret.ExpiresOn.Local().Format("2006-01-02"))
+	}
+	if tags := formatTags(i.secret.Tags); tags != "" {
+		parts = append(parts, tags)
+	}
+
+	return strings.Join(parts, " • ")
+}
+
+// FilterValue includes tags so secrets can be found by e.g. "env=prod"
+func (i item) FilterValue() string {
+	return i.secret.Name + " " + formatTags(i.secret.Tags)
+}
+
+// formatTags renders tags as sorted key=value pairs
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// Model represents the secret list TUI model
+type Model struct {
+	list     list.Model
+	selected string
+	action   Action
+	pending  tea.Cmd // run by Init when the list is shown again
+}
+
+// NewModel creates a new secret list TUI model
+func NewModel(secrets []keyvault.SecretProperties, vaultName string) Model {
+	items := make([]list.Item, len(secrets))
+	for i, secret := range secrets {
+		items[i] = item{secret: secret}
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = fmt.Sprintf("Secrets in %s", vaultName)
+	l.Styles.Title = titleStyle
+	l.SetStatusBarItemName("secret", "secrets")
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{keys.browse, keys.edit}
+	}
+	l.AdditionalFullHelpKeys = l.AdditionalShortHelpKeys
+
+	return Model{list: l}
+}
+
+// Init initializes the model
+func (m Model) Init() tea.Cmd {
+	return m.pending
+}
+
+// Update handles messages and updates the model
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := appStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+		return m, nil
+	case tea.KeyMsg:
+		// While typing a filter every key belongs to the filter input
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, keys.browse):
+			return m.choose(ActionBrowse)
+		case key.Matches(msg, keys.edit):
+			return m.choose(ActionEdit)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// choose records the selected secret and action and exits the list
+func (m Model) choose(action Action) (tea.Model, tea.Cmd) {
+	selected, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return m, nil
+	}
+
+	m.selected = selected.secret.Name
+	m.action = action
+	return m, tea.Quit
+}
+
+// View renders the TUI
+func (m Model) View() string {
+	return appStyle.Render(m.list.View())
+}
+
+// Action returns what the user chose to do, or ActionNone if they quit
+func (m Model) Action() Action {
+	return m.action
+}
+
+// Selected returns the name of the chosen secret
+func (m Model) Selected() string {
+	return m.selected
+}
+
+// Resume clears the last choice and replaces the secrets, keeping the
+// cursor and filter, so the list can be shown again after an action
+func (m Model) Resume(secrets []keyvault.SecretProperties, status string) Model {
+	items := make([]list.Item, len(secrets))
+	for i, secret := range secrets {
+		items[i] = item{secret: secret}
+	}
+
+	m.action = ActionNone
+	m.selected = ""
+	m.pending = m.list.SetItems(items)
+	if status != "" {
+		m.pending = tea.Batch(m.pending, m.list.NewStatusMessage(status))
+	}
+	return m
+}
@@ -0,0 +1,307 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported output formats
+const (
+	JSON  = "json"
+	YAML  = "yaml"
+	Table = "table"
+	Env   = "env"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{JSON, YAML, Table, Env}
+
+// CheckFormat returns an error for unsupported formats. An empty format
+// means the command's default output and is accepted.
+func CheckFormat(format string) error {
+	if format == "" || slices.Contains(Formats, format) {
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q (use %s)", format, strings.Join(Formats, ", "))
+}
+
+// Secret is the structured form of a secret or one of its versions. Value
+// is nil when it is redacted or was not fetched.
+type Secret struct {
+	Name        string            `json:"name" yaml:"name"`
+	Version     string            `json:"version,omitempty" yaml:"version,omitempty"`
+	Value       *string           `json:"value,omitempty" yaml:"value,omitempty"`
+	Enabled     bool              `json:"enabled" yaml:"enabled"`
+	ContentType string            `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	CreatedOn   *time.Time        `json:"createdOn,omitempty" yaml:"createdOn,omitempty"`
+	UpdatedOn   *time.Time        `json:"updatedOn,omitempty" yaml:"updatedOn,omitempty"`
+	NotBefore   *time.Time        `json:"notBefore,omitempty" yaml:"notBefore,omitempty"`
+	ExpiresOn   *time.Time        `json:"expiresOn,omitempty" yaml:"expiresOn,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Error       string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// FromVersion converts a secret version, keeping its value only if reveal
+// is set
+func FromVersion(secretName string, v keyvault.SecretVersion, reveal bool) Secret {
+	s := Secret{
+		Name:        secretName,
+		Version:     v.Version,
+		Enabled:     v.Enabled,
+		ContentType: v.ContentType,
+		CreatedOn:   v.CreatedOn,
+		UpdatedOn:   v.UpdatedOn,
+		NotBefore:   v.NotBefore,
+		ExpiresOn:   v.ExpiresOn,
+		Tags:        v.Tags,
+	}
+	if v.ValueErr != nil {
+		s.Error = v.ValueErr.Error()
+	} else if reveal {
+		value := v.Value
+		s.Value = &value
+	}
+	return s
+}
+
+// FromVersions converts the versions of a secret
+func FromVersions(secretName string, versions []keyvault.SecretVersion, reveal bool) []Secret {
+	secrets := make([]Secret, len(versions))
+	for i, v := range versions {
+		secrets[i] = FromVersion(secretName, v, reveal)
+	}
+	return secrets
+}
+
+// FromProperties converts secret properties, which carry no value
+func FromProperties(p keyvault.SecretProperties) Secret {
+	return Secret{
+		Name:        p.Name,
+		Enabled:     p.Enabled,
+		ContentType: p.ContentType,
+		CreatedOn:   p.CreatedOn,
+		UpdatedOn:   p.UpdatedOn,
+		ExpiresOn:   p.ExpiresOn,
+		Tags:        p.Tags,
+	}
+}
+
+// WriteOne renders a single secret. JSON and YAML produce an object rather
+// than a list.
+func WriteOne(w io.Writer, format string, secret Secret) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, secret)
+	case YAML:
+		return writeYAML(w, secret)
+	default:
+		return Write(w, format, []Secret{secret})
+	}
+}
+
+// Write renders a list of secrets
+func Write(w io.Writer, format string, secrets []Secret) error {
+	switch format {
+	case JSON:
+		if secrets == nil {
+			secrets = []Secret{}
+		}
+		return writeJSON(w, secrets)
+	case YAML:
+		return writeYAML(w, secrets)
+	case Table:
+		return writeTable(w, secrets)
+	case Env:
+		return writeEnv(w, secrets)
+	default:
+		return CheckFormat(format)
+	}
+}
+
+// Deleted is the structured form of a soft-deleted secret
+type Deleted struct {
+	Name               string            `json:"name" yaml:"name"`
+	ContentType        string            `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	DeletedOn          *time.Time        `json:"deletedOn,omitempty" yaml:"deletedOn,omitempty"`
+	ScheduledPurgeDate *time.Time        `json:"scheduledPurgeDate,omitempty" yaml:"scheduledPurgeDate,omitempty"`
+	RecoveryLevel      string            `json:"recoveryLevel,omitempty" yaml:"recoveryLevel,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// FromDeleted converts deleted secrets
+func FromDeleted(secrets []keyvault.DeletedSecret) []Deleted {
+	deleted := make([]Deleted, len(secrets))
+	for i, d := range secrets {
+		deleted[i] = Deleted{
+			Name:               d.Name,
+			ContentType:        d.ContentType,
+			DeletedOn:          d.DeletedOn,
+			ScheduledPurgeDate: d.ScheduledPurgeDate,
+			RecoveryLevel:      d.RecoveryLevel,
+			Tags:               d.Tags,
+		}
+	}
+	return deleted
+}
+
+// WriteDeleted renders a list of deleted secrets. They have no values, so
+// the env format is not supported.
+func WriteDeleted(w io.Writer, format string, secrets []Deleted) error {
+	switch format {
+	case JSON:
+		if secrets == nil {
+			secrets = []Deleted{}
+		}
+		return writeJSON(w, secrets)
+	case YAML:
+		return writeYAML(w, secrets)
+	case Table:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tDELETED\tPURGE DATE\tRECOVERY LEVEL\tTAGS")
+		for _, d := range secrets {
+			fmt.Fprintln(tw, strings.Join([]string{
+				d.Name,
+				formatTime(d.DeletedOn),
+				formatTime(d.ScheduledPurgeDate),
+				d.RecoveryLevel,
+				formatTags(d.Tags),
+			}, "\t"))
+		}
+		return tw.Flush()
+	case Env:
+		return fmt.Errorf("env output is not supported for deleted secrets")
+	default:
+		return CheckFormat(format)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// writeTable renders one row per secret. The value column is only added
+// when at least one value is revealed.
+func writeTable(w io.Writer, secrets []Secret) error {
+	showValues := slices.ContainsFunc(secrets, func(s Secret) bool { return s.Value != nil })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "NAME\tVERSION\tENABLED\tCREATED\tUPDATED\tEXPIRES\tTAGS"
+	if showValues {
+		header += "\tVALUE"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, s := range secrets {
+		row := strings.Join([]string{
+			s.Name,
+			keyvault.ShortVersion(s.Version),
+			fmt.Sprintf("%t", s.Enabled),
+			formatTime(s.CreatedOn),
+			formatTime(s.UpdatedOn),
+			formatTime(s.ExpiresOn),
+			formatTags(s.Tags),
+		}, "\t")
+		if showValues {
+			row += "\t" + tableValue(s)
+		}
+		fmt.Fprintln(tw, row)
+	}
+	return tw.Flush()
+}
+
+// writeEnv renders secrets as shell-quoted NAME=value assignments. Values
+// are the point of this format, so it refuses to run without them.
+func writeEnv(w io.Writer, secrets []Secret) error {
+	seen := make(map[string]string, len(secrets))
+	for _, s := range secrets {
+		key := EnvName(s.Name)
+		if prev, ok := seen[key]; ok {
+			return fmt.Errorf("env output needs one value per variable: %q and %q both map to %s", prev, s.Name, key)
+		}
+		seen[key] = s.Name
+
+		if s.Error != "" {
+			fmt.Fprintf(w, "# %s: %s\n", key, s.Error)
+			continue
+		}
+		if s.Value == nil {
+			return fmt.Errorf("env output requires --reveal")
+		}
+		fmt.Fprintf(w, "%s=%s\n", key, shellQuote(*s.Value))
+	}
+	return nil
+}
+
+// EnvName converts a secret name such as "db-password" into an environment
+// variable name such as "DB_PASSWORD"
+func EnvName(name string) string {
+	var b strings.Builder
+	for i, r := range strings.ToUpper(name) {
+		switch {
+		case r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	pairs := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		pairs = append(pairs, key+"="+tags[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// tableValue keeps table rows on one line
+func tableValue(s Secret) string {
+	switch {
+	case s.Error != "":
+		return "<" + s.Error + ">"
+	case s.Value == nil:
+		return "<redacted>"
+	default:
+		return strings.ReplaceAll(*s.Value, "\n", `\n`)
+	}
+}
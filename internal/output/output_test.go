@@ -0,0 +1,227 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"gopkg.in/yaml.v3"
+)
+
+func value(s string) *string {
+	return &s
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{"", JSON, YAML, Table, Env} {
+		if err := CheckFormat(format); err != nil {
+			t.Errorf("CheckFormat(%q): %v", format, err)
+		}
+	}
+	if err := CheckFormat("xml"); err == nil {
+		t.Error("CheckFormat(xml) accepted")
+	}
+}
+
+func TestFromVersion(t *testing.T) {
+	v := keyvault.SecretVersion{Version: "abc", Value: "s3cret", Enabled: true, Tags: map[string]string{"env": "dev"}}
+
+	tests := []struct {
+		name      string
+		v         keyvault.SecretVersion
+		reveal    bool
+		wantValue *string
+		wantErr   string
+	}{
+		{"redacted", v, false, nil, ""},
+		{"revealed", v, true, value("s3cret"), ""},
+		{"revealed empty value", keyvault.SecretVersion{Version: "abc"}, true, value(""), ""},
+		{"fetch failed", keyvault.SecretVersion{Version: "abc", ValueErr: errors.New("throttled")}, true, nil, "throttled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromVersion("db", tt.v, tt.reveal)
+			if got.Name != "db" || got.Version != "abc" {
+				t.Errorf("Name/Version = %q/%q", got.Name, got.Version)
+			}
+			if (got.Value == nil) != (tt.wantValue == nil) || (got.Value != nil && *got.Value != *tt.wantValue) {
+				t.Errorf("Value = %v, want %v", got.Value, tt.wantValue)
+			}
+			if got.Error != tt.wantErr {
+				t.Errorf("Error = %q, want %q", got.Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"db-password":  "DB_PASSWORD",
+		"api.key":      "API_KEY",
+		"already_OK_1": "ALREADY_OK_1",
+		"1password":    "_1PASSWORD",
+		"ünïcode":      "_N_CODE",
+	}
+	for name, want := range tests {
+		if got := EnvName(name); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWriteEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []Secret
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "quoted values",
+			secrets: []Secret{{Name: "db-password", Value: value("it's $HOME")}, {Name: "multi", Value: value("a\nb")}},
+			want:    "DB_PASSWORD='it'\\''s $HOME'\nMULTI='a\nb'\n",
+		},
+		{
+			name:    "fetch error becomes a comment",
+			secrets: []Secret{{Name: "a", Value: value("1")}, {Name: "b", Error: "secret version is disabled"}},
+			want:    "A='1'\n# B: secret version is disabled\n",
+		},
+		{
+			name:    "needs reveal",
+			secrets: []Secret{{Name: "a"}},
+			wantErr: "requires --reveal",
+		},
+		{
+			name:    "colliding names",
+			secrets: []Secret{{Name: "db-password", Value: value("1")}, {Name: "db.password", Value: value("2")}},
+			wantErr: "both map to DB_PASSWORD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, Env, tt.secrets)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Write error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	secrets := []Secret{
+		{Name: "db", Version: "0123456789abcdef", Enabled: true, CreatedOn: &created, Tags: map[string]string{"z": "1", "a": "2"}},
+		{Name: "off"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Table, secrets); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines:\n%s", len(lines), buf.String())
+	}
+	if strings.Contains(lines[0], "VALUE") {
+		t.Errorf("value column shown without revealed values: %q", lines[0])
+	}
+	for _, want := range []string{"db", "01234567", "true", "2024-01-02T02:04:05Z", "a=2,z=1"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q is missing %q", lines[1], want)
+		}
+	}
+	if strings.Contains(lines[1], "0123456789") {
+		t.Errorf("row %q has the full version ID", lines[1])
+	}
+
+	// Revealing any value adds the column; the others are marked
+	secrets[0].Value = value("line1\nline2")
+	secrets[1].Error = "secret version is disabled"
+	buf.Reset()
+	if err := Write(&buf, Table, secrets); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"VALUE", `line1\nline2`, "<secret version is disabled>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteStructured(t *testing.T) {
+	secrets := []Secret{{Name: "db", Enabled: true, Value: value("v")}, {Name: "redacted"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, JSON, secrets); err != nil {
+		t.Fatalf("Write json: %v", err)
+	}
+	var fromJSON []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &fromJSON); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, buf.String())
+	}
+	if len(fromJSON) != 2 || fromJSON[0]["value"] != "v" {
+		t.Errorf("json = %v", fromJSON)
+	}
+	if _, ok := fromJSON[1]["value"]; ok {
+		t.Errorf("redacted secret has a value: %v", fromJSON[1])
+	}
+
+	buf.Reset()
+	if err := Write(&buf, YAML, secrets); err != nil {
+		t.Fatalf("Write yaml: %v", err)
+	}
+	var fromYAML []Secret
+	if err := yaml.Unmarshal(buf.Bytes(), &fromYAML); err != nil {
+		t.Fatalf("invalid yaml: %v\n%s", err, buf.String())
+	}
+	if len(fromYAML) != 2 || fromYAML[0].Value == nil || *fromYAML[0].Value != "v" || fromYAML[1].Value != nil {
+		t.Errorf("yaml = %+v", fromYAML)
+	}
+
+	// An empty list is still a JSON array, and WriteOne writes an object
+	buf.Reset()
+	if err := Write(&buf, JSON, nil); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty json = %q, %v", buf.String(), err)
+	}
+	buf.Reset()
+	if err := WriteOne(&buf, JSON, secrets[0]); err != nil || !strings.HasPrefix(buf.String(), "{") {
+		t.Errorf("WriteOne json = %q, %v", buf.String(), err)
+	}
+
+	if err := Write(&buf, "xml", secrets); err == nil {
+		t.Error("Write accepted an unknown format")
+	}
+}
+
+func TestWriteDeleted(t *testing.T) {
+	purge := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	deleted := FromDeleted([]keyvault.DeletedSecret{{Name: "gone", ScheduledPurgeDate: &purge, RecoveryLevel: "Recoverable"}})
+
+	var buf bytes.Buffer
+	if err := WriteDeleted(&buf, Table, deleted); err != nil {
+		t.Fatalf("WriteDeleted: %v", err)
+	}
+	for _, want := range []string{"PURGE DATE", "gone", "2024-04-01T00:00:00Z", "Recoverable"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("table is missing %q:\n%s", want, buf.String())
+		}
+	}
+	if err := WriteDeleted(&buf, Env, deleted); err == nil {
+		t.Error("env output accepted for deleted secrets")
+	}
+}
@@ -0,0 +1,275 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpKind is the kind of a diff operation
+type OpKind int
+
+const (
+	// Equal means the elements are in both sequences
+	Equal OpKind = iota
+	// Delete means the elements are only in the old sequence
+	Delete
+	// Insert means the elements are only in the new sequence
+	Insert
+)
+
+// Op is a run of elements of one kind. A[AStart:AEnd] are the old elements
+// and B[BStart:BEnd] the new ones; one of the ranges is empty for deletes
+// and inserts.
+type Op struct {
+	Kind   OpKind
+	AStart int
+	AEnd   int
+	BStart int
+	BEnd   int
+}
+
+// Diff computes a shortest edit script turning a into b using Myers'
+// O(ND) algorithm, so similar inputs are cheap even when they are long
+func Diff[T comparable](a, b []T) []Op {
+	n, m := len(a), len(b)
+	maxD := n + m
+
+	// v[offset+k] is the furthest x reached on diagonal k. trace[d] keeps
+	// the diagonals -d..d after step d for backtracking.
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // step down: insert from b
+			} else {
+				x = v[offset+k-1] + 1 // step right: delete from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				break search
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	return backtrack(trace, n, m)
+}
+
+// backtrack walks the trace from the end to recover the edit script
+func backtrack(trace [][]int, n, m int) []Op {
+	type step struct {
+		kind OpKind
+		x, y int
+	}
+	var steps []step
+
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			steps = append(steps, step{Equal, x, y})
+		}
+		if x == prevX {
+			y--
+			steps = append(steps, step{Insert, x, y})
+		} else {
+			x--
+			steps = append(steps, step{Delete, x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		steps = append(steps, step{Equal, x, y})
+	}
+
+	// Steps were collected backwards; merge them into runs going forwards
+	var ops []Op
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+		if len(ops) > 0 && ops[len(ops)-1].Kind == s.kind {
+			last := &ops[len(ops)-1]
+			switch s.kind {
+			case Equal:
+				last.AEnd++
+				last.BEnd++
+			case Delete:
+				last.AEnd++
+			case Insert:
+				last.BEnd++
+			}
+			continue
+		}
+
+		op := Op{Kind: s.kind, AStart: s.x, AEnd: s.x, BStart: s.y, BEnd: s.y}
+		switch s.kind {
+		case Equal:
+			op.AEnd++
+			op.BEnd++
+		case Delete:
+			op.AEnd++
+		case Insert:
+			op.BEnd++
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// Lines splits text into lines for diffing. Empty text has no lines, so
+// diffing it against a value shows the value as added rather than changed.
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Unified renders a unified diff of two texts with the given number of
+// context lines around each change. It returns an empty string when the
+// texts are equal.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	a, b := Lines(oldText), Lines(newText)
+	ops := Diff(a, b)
+
+	changed := false
+	for _, op := range ops {
+		if op.Kind != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for _, h := range hunks(ops, context) {
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(h.aStart, h.aEnd), hunkRange(h.bStart, h.bEnd))
+		for _, op := range h.ops {
+			switch op.Kind {
+			case Equal:
+				for _, line := range a[op.AStart:op.AEnd] {
+					out.WriteString(" " + line + "\n")
+				}
+			case Delete:
+				for _, line := range a[op.AStart:op.AEnd] {
+					out.WriteString("-" + line + "\n")
+				}
+			case Insert:
+				for _, line := range b[op.BStart:op.BEnd] {
+					out.WriteString("+" + line + "\n")
+				}
+			}
+		}
+	}
+
+	return out.String()
+}
+
+type hunk struct {
+	aStart, aEnd int
+	bStart, bEnd int
+	ops          []Op
+}
+
+// hunks groups changes that are within 2*context lines of each other,
+// trimming the surrounding equal runs to context lines
+func hunks(ops []Op, context int) []hunk {
+	var result []hunk
+	var cur *hunk
+
+	for i, op := range ops {
+		if op.Kind != Equal {
+			if cur == nil {
+				cur = &hunk{aStart: op.AStart, bStart: op.BStart}
+			}
+			cur.ops = append(cur.ops, op)
+			cur.aEnd, cur.bEnd = op.AEnd, op.BEnd
+			continue
+		}
+
+		size := op.AEnd - op.AStart
+		if cur == nil {
+			// Leading context for the next hunk
+			if i+1 < len(ops) {
+				lead := min(size, context)
+				cur = &hunk{aStart: op.AEnd - lead, bStart: op.BEnd - lead}
+				if lead > 0 {
+					cur.ops = append(cur.ops, Op{Equal, op.AEnd - lead, op.AEnd, op.BEnd - lead, op.BEnd})
+				}
+			}
+			continue
+		}
+
+		if i+1 < len(ops) && size <= 2*context {
+			// Small gap: keep it inside the current hunk
+			cur.ops = append(cur.ops, op)
+			cur.aEnd, cur.bEnd = op.AEnd, op.BEnd
+			continue
+		}
+
+		// Close the hunk with trailing context
+		trail := min(size, context)
+		if trail > 0 {
+			cur.ops = append(cur.ops, Op{Equal, op.AStart, op.AStart + trail, op.BStart, op.BStart + trail})
+		}
+		cur.aEnd, cur.bEnd = op.AStart+trail, op.BStart+trail
+		result = append(result, *cur)
+		cur = nil
+
+		// The rest of this run may lead into the next hunk
+		if i+1 < len(ops) {
+			lead := min(size-trail, context)
+			cur = &hunk{aStart: op.AEnd - lead, bStart: op.BEnd - lead}
+			if lead > 0 {
+				cur.ops = append(cur.ops, Op{Equal, op.AEnd - lead, op.AEnd, op.BEnd - lead, op.BEnd})
+			}
+		}
+	}
+
+	if cur != nil && len(cur.ops) > 0 {
+		result = append(result, *cur)
+	}
+	return result
+}
+
+// hunkRange formats a 0-based half-open range as a unified diff range
+func hunkRange(start, end int) string {
+	length := end - start
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
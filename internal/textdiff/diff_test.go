@@ -0,0 +1,152 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\nb", []string{"a", "b"}},
+		{"a\n", []string{"a", ""}},
+		{"\n", []string{"", ""}},
+	}
+	for _, tt := range tests {
+		got := Lines(tt.text)
+		if len(got) != len(tt.want) || (tt.want == nil) != (got == nil) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.text, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Lines(%q) = %q, want %q", tt.text, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		wantEdit int // deleted plus inserted elements of a shortest script
+	}{
+		{"both empty", "", "", 0},
+		{"equal", "abc", "abc", 0},
+		{"insert into empty", "", "abc", 3},
+		{"delete everything", "abc", "", 3},
+		{"insert at start", "bcd", "abcd", 1},
+		{"insert at end", "abc", "abcd", 1},
+		{"delete in middle", "abcd", "abd", 1},
+		{"replace", "abc", "axc", 2},
+		{"unrelated", "abc", "xyz", 6},
+		{"classic", "abcabba", "cbabac", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+			ops := Diff(a, b)
+
+			// Replaying the script must turn a into b, covering both
+			// sequences in order without gaps
+			var got []string
+			edits, aPos, bPos := 0, 0, 0
+			for i, op := range ops {
+				if op.AStart != aPos || op.BStart != bPos {
+					t.Fatalf("op %d %+v does not continue at a[%d], b[%d]", i, op, aPos, bPos)
+				}
+				if i > 0 && ops[i-1].Kind == op.Kind {
+					t.Errorf("ops %d and %d are both kind %d and should be merged", i-1, i, op.Kind)
+				}
+				switch op.Kind {
+				case Equal:
+					got = append(got, a[op.AStart:op.AEnd]...)
+				case Delete:
+					if op.BStart != op.BEnd {
+						t.Errorf("delete %+v has a b range", op)
+					}
+					edits += op.AEnd - op.AStart
+				case Insert:
+					if op.AStart != op.AEnd {
+						t.Errorf("insert %+v has an a range", op)
+					}
+					got = append(got, b[op.BStart:op.BEnd]...)
+					edits += op.BEnd - op.BStart
+				}
+				aPos, bPos = op.AEnd, op.BEnd
+			}
+			if aPos != len(a) || bPos != len(b) {
+				t.Errorf("script ends at a[%d], b[%d], want a[%d], b[%d]", aPos, bPos, len(a), len(b))
+			}
+			if strings.Join(got, "") != tt.b {
+				t.Errorf("replayed script gives %q, want %q", strings.Join(got, ""), tt.b)
+			}
+			if edits != tt.wantEdit {
+				t.Errorf("script has %d edits, want %d", edits, tt.wantEdit)
+			}
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb", new: "a\nb", context: 3,
+			want: "",
+		},
+		{
+			name: "from empty",
+			old:  "", new: "a", context: 3,
+			want: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "to empty",
+			old:  "a\nb", new: "", context: 3,
+			want: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "insert at top",
+			old:  "a\nb\nc", new: "x\na\nb\nc", context: 3,
+			want: "--- old\n+++ new\n@@ -1,3 +1,4 @@\n+x\n a\n b\n c\n",
+		},
+		{
+			name: "context is trimmed",
+			old:  "1\n2\n3\n4", new: "1\nX\n3\n4", context: 1,
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n 1\n-2\n+X\n 3\n",
+		},
+		{
+			name: "distant changes split into hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10", new: "1\nTWO\n3\n4\n5\n6\n7\n8\nNINE\n10", context: 1,
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n 1\n-2\n+TWO\n 3\n@@ -8,3 +8,3 @@\n 8\n-9\n+NINE\n 10\n",
+		},
+		{
+			name: "close changes share a hunk",
+			old:  "1\n2\n3\n4\n5", new: "1\nTWO\n3\nFOUR\n5", context: 1,
+			want: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+TWO\n 3\n-4\n+FOUR\n 5\n",
+		},
+		{
+			name: "trailing newline removed",
+			old:  "a\nb\n", new: "a\nb", context: 3,
+			want: "--- old\n+++ new\n@@ -1,3 +1,2 @@\n a\n b\n-\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.old, tt.new, tt.context); got != tt.want {
+				t.Errorf("Unified =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/bayhaqi/kv/internal/difftui"
 	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -32,65 +35,244 @@ var (
 				Foreground(lipgloss.Color("#10B981")).
 				Bold(true)
 
+	markedBadgeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FBBF24")).
+				Bold(true)
+
+	disabledBadgeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#EF4444")).
+				Bold(true)
+
 	lineNumStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#6B7280")).
 			Width(4).
 			Align(lipgloss.Right)
+
+	spinnerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7D56F4"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#EF4444"))
 )
 
+// Action is what the user asked to do with the selected version
+type Action int
+
+const (
+	// ActionNone means the user quit the browser
+	ActionNone Action = iota
+	// ActionRollback re-publishes the selected version's value as the latest version
+	ActionRollback
+	// ActionRollbackWithMetadata also restores the selected version's tags and content type
+	ActionRollbackWithMetadata
+)
+
+// valueLoadedMsg is sent when a version's value has been fetched
+type valueLoadedMsg struct {
+	version string
+	value   string
+	err     error
+}
+
+// enabledChangedMsg is sent when a version has been enabled or disabled
+type enabledChangedMsg struct {
+	version string
+	enabled bool
+	err     error
+}
+
 // Model represents the TUI model
 type Model struct {
+	ctx        context.Context
+	store      keyvault.SecretStore
 	versions   []keyvault.SecretVersion
 	secretName string
 	currentIdx int
 	viewport   viewport.Model
+	spinner    spinner.Model
 	ready      bool
 	width      int
 	height     int
+
+	// Values are fetched on demand and cached by version ID
+	values  map[string]string
+	errs    map[string]error
+	loading map[string]bool
+
+	// marked is the version ID chosen with "m" as the base for comparisons
+	marked string
+	// diff is the comparison being shown, if any
+	diff *difftui.Model
+
+	// confirmToggle is the version ID waiting for y/n after "d"
+	confirmToggle string
+
+	action Action
+	status string
 }
 
-// NewModel creates a new TUI model
-func NewModel(versions []keyvault.SecretVersion, secretName string) Model {
+// NewModel creates a new TUI model. Versions only need their properties;
+// values are fetched from the store when a version is first viewed.
+func NewModel(ctx context.Context, store keyvault.SecretStore, versions []keyvault.SecretVersion, secretName string) Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = spinnerStyle
+
 	return Model{
+		ctx:        ctx,
+		store:      store,
 		versions:   versions,
 		secretName: secretName,
 		currentIdx: 0,
+		spinner:    s,
+		values:     make(map[string]string),
+		errs:       make(map[string]error),
+		loading:    make(map[string]bool),
 	}
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return nil
+	return m.ensureValue()
+}
+
+// ensureValue starts fetching the current version's value unless it is
+// already cached or in flight. A failed fetch is retried, so navigating
+// back to a version recovers from throttling or a network error.
+func (m *Model) ensureValue() tea.Cmd {
+	if len(m.versions) == 0 {
+		return nil
+	}
+	version := m.versions[m.currentIdx].Version
+	delete(m.errs, version)
+	return m.loadValue(version)
+}
+
+// loadValue starts fetching a version's value unless it is already cached,
+// failed or in flight. The spinner only gets a new tick loop when nothing
+// else is loading, since each loop keeps itself going.
+func (m *Model) loadValue(version string) tea.Cmd {
+	if _, ok := m.values[version]; ok {
+		return nil
+	}
+	if m.errs[version] != nil || m.loading[version] {
+		return nil
+	}
+
+	tick := !m.isLoading()
+	m.loading[version] = true
+	if !tick {
+		return m.fetchValue(version)
+	}
+	return tea.Batch(m.fetchValue(version), m.spinner.Tick)
+}
+
+// fetchValue fetches a version's value in the background
+func (m Model) fetchValue(version string) tea.Cmd {
+	ctx, store, secretName := m.ctx, m.store, m.secretName
+	return func() tea.Msg {
+		secret, err := store.GetSecret(ctx, secretName, version)
+		if err != nil {
+			return valueLoadedMsg{version: version, err: err}
+		}
+		return valueLoadedMsg{version: version, value: secret.Value}
+	}
+}
+
+// isLoading reports whether any value is still being fetched
+func (m Model) isLoading() bool {
+	for _, loading := range m.loading {
+		if loading {
+			return true
+		}
+	}
+	return false
 }
 
 // Update handles messages and updates the model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	// While a comparison is open it receives all input
+	if m.diff != nil {
+		switch msg.(type) {
+		case difftui.CloseMsg:
+			m.diff = nil
+			return m, nil
+		case tea.KeyMsg, tea.MouseMsg:
+			updated, cmd := m.diff.Update(msg)
+			diff := updated.(difftui.Model)
+			m.diff = &diff
+			return m, cmd
+		}
+	}
+
+	// A pending enable/disable takes the next key as its answer
+	if key, ok := msg.(tea.KeyMsg); ok && m.confirmToggle != "" {
+		return m.answerToggle(key.String())
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc", "q", "ctrl+c":
 			return m, tea.Quit
 		case "left", "h":
+			m.status = ""
 			if m.currentIdx > 0 {
 				m.currentIdx--
 				m.updateViewportContent()
 			}
-			return m, nil
+			return m, m.ensureValue()
 		case "right", "l":
+			m.status = ""
 			if m.currentIdx < len(m.versions)-1 {
 				m.currentIdx++
 				m.updateViewportContent()
 			}
+			return m, m.ensureValue()
+		case "m":
+			return m.toggleMark(), nil
+		case "c":
+			return m.compare()
+		case "r":
+			return m.requestRollback(ActionRollback)
+		case "R":
+			return m.requestRollback(ActionRollbackWithMetadata)
+		case "d":
+			return m.requestToggle(), nil
+		}
+	case valueLoadedMsg:
+		delete(m.loading, msg.version)
+		if msg.err != nil {
+			m.errs[msg.version] = msg.err
+		} else {
+			m.values[msg.version] = msg.value
+		}
+		if m.ready && m.versions[m.currentIdx].Version == msg.version {
+			m.updateViewportContent()
+		}
+		return m, nil
+	case enabledChangedMsg:
+		return m.toggled(msg)
+	case spinner.TickMsg:
+		if !m.isLoading() {
 			return m, nil
 		}
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 
 		footerHeight := 3 // Footer with secret name, version, and help
 
+		if m.diff != nil {
+			updated, _ := m.diff.Update(msg)
+			diff := updated.(difftui.Model)
+			m.diff = &diff
+		}
+
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width-4, msg.Height-footerHeight-2) // -4 for box border, -2 for box padding
 			m.ready = true
@@ -108,20 +290,192 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// toggleMark marks the current version as the base for comparisons, or
+// clears the mark if it is already marked
+func (m Model) toggleMark() Model {
+	version := m.versions[m.currentIdx].Version
+	if m.marked == version {
+		m.marked = ""
+		m.status = "Mark cleared"
+		return m
+	}
+
+	m.marked = version
+	m.status = fmt.Sprintf("Marked %s, select another version and press c", keyvault.ShortVersion(version))
+	return m
+}
+
+// compare opens a diff of the current version against the marked one, or
+// against the latest version if none is marked. The older version is shown
+// on the left.
+func (m Model) compare() (tea.Model, tea.Cmd) {
+	otherIdx := 0
+	if m.marked != "" {
+		for i, v := range m.versions {
+			if v.Version == m.marked {
+				otherIdx = i
+			}
+		}
+	}
+	if otherIdx == m.currentIdx {
+		m.status = "Mark a version with m, then select another to compare"
+		return m, nil
+	}
+
+	// Versions are sorted newest first, so the higher index is older
+	oldIdx, newIdx := otherIdx, m.currentIdx
+	if oldIdx < newIdx {
+		oldIdx, newIdx = newIdx, oldIdx
+	}
+	oldVersion, newVersion := m.versions[oldIdx].Version, m.versions[newIdx].Version
+
+	oldValue, oldOK := m.values[oldVersion]
+	newValue, newOK := m.values[newVersion]
+	if !oldOK || !newOK {
+		m.status = "Value not loaded yet"
+		return m, tea.Batch(m.loadValue(oldVersion), m.loadValue(newVersion))
+	}
+
+	diff := difftui.NewCompareModel(oldValue, newValue, m.secretName, m.versionTitle(oldIdx), m.versionTitle(newIdx))
+	updated, _ := diff.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	diff = updated.(difftui.Model)
+	m.diff = &diff
+	m.status = ""
+	return m, nil
+}
+
+// versionTitle labels a version for the comparison view
+func (m Model) versionTitle(idx int) string {
+	title := "Version " + keyvault.ShortVersion(m.versions[idx].Version)
+	if idx == 0 {
+		title += " (latest)"
+	}
+	return title
+}
+
+// requestRollback exits the browser so the caller can roll back to the
+// selected version, once its value is available
+func (m Model) requestRollback(action Action) (tea.Model, tea.Cmd) {
+	version := m.versions[m.currentIdx].Version
+
+	if m.currentIdx == 0 {
+		m.status = "Already the latest version"
+		return m, nil
+	}
+	if _, ok := m.values[version]; !ok {
+		m.status = "Value not loaded yet"
+		return m, nil
+	}
+
+	m.action = action
+	return m, tea.Quit
+}
+
+// requestToggle asks for confirmation before enabling or disabling the
+// current version
+func (m Model) requestToggle() Model {
+	v := m.versions[m.currentIdx]
+	verb := "Disable"
+	if !v.Enabled {
+		verb = "Enable"
+	}
+
+	m.confirmToggle = v.Version
+	m.status = fmt.Sprintf("%s version %s? (y/n)", verb, keyvault.ShortVersion(v.Version))
+	if m.currentIdx == 0 && v.Enabled {
+		m.status = fmt.Sprintf("Disable the latest version %s? Reading the secret will fail (y/n)", keyvault.ShortVersion(v.Version))
+	}
+	return m
+}
+
+// answerToggle handles the answer to requestToggle. Only "y" goes ahead.
+func (m Model) answerToggle(key string) (tea.Model, tea.Cmd) {
+	version := m.confirmToggle
+	m.confirmToggle = ""
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if key != "y" && key != "Y" {
+		m.status = "Cancelled"
+		return m, nil
+	}
+
+	enabled := true
+	for _, v := range m.versions {
+		if v.Version == version {
+			enabled = !v.Enabled
+		}
+	}
+
+	m.status = "Updating..."
+	ctx, store, secretName := m.ctx, m.store, m.secretName
+	return m, func() tea.Msg {
+		_, err := store.UpdateSecretProperties(ctx, secretName, version, keyvault.SecretAttributes{Enabled: &enabled})
+		return enabledChangedMsg{version: version, enabled: enabled, err: err}
+	}
+}
+
+// toggled records the result of enabling or disabling a version. A version
+// that could not be read while disabled is fetched again once enabled.
+func (m Model) toggled(msg enabledChangedMsg) (tea.Model, tea.Cmd) {
+	short := keyvault.ShortVersion(msg.version)
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Error updating %s: %v", short, msg.err)
+		return m, nil
+	}
+
+	// Copy before changing so models sharing the slice are not affected
+	m.versions = append([]keyvault.SecretVersion(nil), m.versions...)
+	for i := range m.versions {
+		if m.versions[i].Version == msg.version {
+			m.versions[i].Enabled = msg.enabled
+		}
+	}
+
+	// Say how to undo it, since a disabled latest version breaks every read
+	// of the secret
+	if !msg.enabled {
+		m.status = fmt.Sprintf("Disabled %s, press d to enable it again", short)
+		if len(m.versions) > 0 && m.versions[0].Version == msg.version {
+			m.status = fmt.Sprintf("Disabled the latest version %s: reading the secret fails until you enable it with d or roll back to an older version with r", short)
+		}
+		return m, nil
+	}
+
+	m.status = fmt.Sprintf("Enabled %s", short)
+	delete(m.errs, msg.version)
+	cmd := m.loadValue(msg.version)
+	m.updateViewportContent()
+	return m, cmd
+}
+
 // updateViewportContent updates the viewport with the current version details
 func (m *Model) updateViewportContent() {
 	if len(m.versions) == 0 {
 		return
 	}
 
-	version := m.versions[m.currentIdx]
+	version := m.versions[m.currentIdx].Version
+
+	if err := m.errs[version]; err != nil {
+		m.viewport.SetContent(errorStyle.Render(fmt.Sprintf("Error fetching value: %v", err)))
+		m.viewport.GotoTop()
+		return
+	}
+
+	value, ok := m.values[version]
+	if !ok {
+		// Still loading; View renders the spinner instead
+		m.viewport.SetContent("")
+		return
+	}
 
 	// Just display the secret value with line numbers
 	maxWidth := m.viewport.Width - 8 // Account for line numbers and padding
 	if maxWidth < 20 {
 		maxWidth = 20
 	}
-	wrappedValue := wrapTextWithLineNumbers(version.Value, maxWidth)
+	wrappedValue := wrapTextWithLineNumbers(value, maxWidth)
 
 	m.viewport.SetContent(wrappedValue)
 	m.viewport.GotoTop()
@@ -213,37 +567,77 @@ func (m Model) View() string {
 		return "\n  Initializing..."
 	}
 
-	// Build the content box with viewport
+	if m.diff != nil {
+		return m.diff.View()
+	}
+
+	// Build the content box with viewport, or a spinner while the value loads
+	body := m.viewport.View()
+	if m.loading[m.versions[m.currentIdx].Version] {
+		body = fmt.Sprintf("%s Loading value...", m.spinner.View())
+	}
 	content := boxStyle.
 		Width(m.width - 2).
 		Height(m.height - 4).
-		Render(m.viewport.View())
+		Render(body)
 
 	// Build footer with secret name and version
-	versionName := m.versions[m.currentIdx].Version
-	if len(versionName) > 8 {
-		versionName = versionName[:8]
-	}
+	versionName := keyvault.ShortVersion(m.versions[m.currentIdx].Version)
 
 	// Check if this is the latest version (index 0)
 	latestBadge := ""
 	if m.currentIdx == 0 {
 		latestBadge = latestBadgeStyle.Render(" [latest]")
 	}
+	if m.versions[m.currentIdx].Version == m.marked {
+		latestBadge += markedBadgeStyle.Render(" [marked]")
+	}
+	if !m.versions[m.currentIdx].Enabled {
+		latestBadge += disabledBadgeStyle.Render(" [disabled]")
+	}
+
+	status := ""
+	if m.status != "" {
+		status = " • " + m.status
+	}
 
 	footer := footerStyle.Render(
-		fmt.Sprintf("%s • %s (%d/%d)%s",
+		fmt.Sprintf("%s • %s (%d/%d)%s%s",
 			secretNameStyle.Render(m.secretName),
 			versionStyle.Render(versionName),
 			m.currentIdx+1,
 			len(m.versions),
 			latestBadge,
+			status,
 		),
 	)
 
 	// Help text
-	help := footerStyle.Render("← → Navigate • ↑↓ Scroll • ESC/Q Quit")
+	help := footerStyle.Render("← → Navigate • ↑↓ Scroll • m Mark • c Compare • r/R Rollback (+metadata) • d Disable/Enable • ESC/Q Quit")
 
 	// Combine all parts
 	return fmt.Sprintf("%s\n%s\n%s", content, footer, help)
 }
+
+// Action returns what the user asked to do, or ActionNone if they quit
+func (m Model) Action() Action {
+	return m.action
+}
+
+// Selected returns the selected version, including its value if loaded
+func (m Model) Selected() keyvault.SecretVersion {
+	version := m.versions[m.currentIdx]
+	version.Value = m.values[version.Version]
+	return version
+}
+
+// Resume clears the last action and replaces the versions, keeping the
+// value cache, so the browser can be shown again after an action. The
+// latest version is selected.
+func (m Model) Resume(versions []keyvault.SecretVersion, status string) Model {
+	m.versions = versions
+	m.currentIdx = 0
+	m.action = ActionNone
+	m.status = status
+	return m
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// flakyStore fails the first GetSecret of each version
+type flakyStore struct {
+	*keyvault.MemoryStore
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+func (s *flakyStore) GetSecret(ctx context.Context, name, version string) (*keyvault.SecretVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.failed[version] {
+		s.failed[version] = true
+		return nil, keyvault.ErrThrottled
+	}
+	return s.MemoryStore.GetSecret(ctx, name, version)
+}
+
+func newTestModel(t *testing.T, store keyvault.SecretStore, memory *keyvault.MemoryStore, values ...string) Model {
+	t.Helper()
+
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, value := range values {
+		createdOn := created.Add(time.Duration(i) * time.Hour)
+		if _, err := memory.AddVersion("db", keyvault.SecretVersion{Value: value, Enabled: true, CreatedOn: &createdOn}); err != nil {
+			t.Fatalf("AddVersion: %v", err)
+		}
+	}
+	versions, err := memory.ListSecretVersions(ctx, "db")
+	if err != nil {
+		t.Fatalf("ListSecretVersions: %v", err)
+	}
+
+	m := NewModel(ctx, store, versions, "db")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return updated.(Model)
+}
+
+// messages runs cmd and returns the messages it produces, flattening
+// batches and skipping spinner ticks, which are counted separately
+func messages(cmd tea.Cmd) (msgs []tea.Msg, ticks int) {
+	if cmd == nil {
+		return nil, 0
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			m, n := messages(c)
+			msgs = append(msgs, m...)
+			ticks += n
+		}
+	case spinner.TickMsg:
+		ticks++
+	case nil:
+	default:
+		msgs = append(msgs, msg)
+	}
+	return msgs, ticks
+}
+
+// feed sends msgs to the model, running any commands they return
+func feed(t *testing.T, m Model, msgs ...tea.Msg) Model {
+	t.Helper()
+	for _, msg := range msgs {
+		updated, cmd := m.Update(msg)
+		m = updated.(Model)
+		more, _ := messages(cmd)
+		m = feed(t, m, more...)
+	}
+	return m
+}
+
+func key(k string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func TestFailedFetchIsRetriedOnNavigation(t *testing.T) {
+	memory := keyvault.NewMemoryStore()
+	store := &flakyStore{MemoryStore: memory, failed: make(map[string]bool)}
+	m := newTestModel(t, store, memory, "old", "new")
+
+	msgs, _ := messages(m.Init())
+	m = feed(t, m, msgs...)
+	latest := m.versions[0].Version
+	if !errors.Is(m.errs[latest], keyvault.ErrThrottled) {
+		t.Fatalf("errs[latest] = %v, want the throttling error", m.errs[latest])
+	}
+
+	// Away and back again retries the failed version
+	m = feed(t, m, key("l"), key("h"))
+	if m.errs[latest] != nil {
+		t.Errorf("error not cleared after navigating back: %v", m.errs[latest])
+	}
+	if m.values[latest] != "new" {
+		t.Errorf("values[latest] = %q, want the value fetched on retry", m.values[latest])
+	}
+}
+
+func TestSpinnerTicksOnlyOnce(t *testing.T) {
+	memory := keyvault.NewMemoryStore()
+	m := newTestModel(t, memory, memory, "a", "b", "c")
+
+	var ticks int
+	for _, v := range m.versions {
+		cmd := m.loadValue(v.Version)
+		if cmd == nil {
+			t.Fatalf("loadValue(%s) returned no command", v.Version)
+		}
+		// Only count the ticks; the fetches are not delivered so all
+		// three versions stay in flight
+		if batch, ok := cmd().(tea.BatchMsg); ok {
+			for _, c := range batch {
+				if _, isTick := c().(spinner.TickMsg); isTick {
+					ticks++
+				}
+			}
+		}
+	}
+	if ticks != 1 {
+		t.Errorf("started %d spinner tick loops, want 1", ticks)
+	}
+}
+
+func TestDisableLatestThenRollBack(t *testing.T) {
+	ctx := context.Background()
+	memory := keyvault.NewMemoryStore()
+	m := newTestModel(t, memory, memory, "old", "new")
+	msgs, _ := messages(m.Init())
+	m = feed(t, m, msgs...)
+	latest := m.versions[0].Version
+
+	m = feed(t, m, key("d"), key("y"))
+	if m.versions[0].Enabled {
+		t.Fatal("latest version still enabled after d, y")
+	}
+	if !strings.Contains(m.status, "enable it with d") || !strings.Contains(m.status, "roll back") {
+		t.Errorf("status = %q, want it to explain how to recover", m.status)
+	}
+	if _, err := memory.GetSecret(ctx, "db", ""); !errors.Is(err, keyvault.ErrSecretDisabled) {
+		t.Fatalf("GetSecret latest error = %v, want ErrSecretDisabled", err)
+	}
+
+	// The older version can still be rolled back to
+	m = feed(t, m, key("l"))
+	m = feed(t, m, key("r"))
+	if m.Action() != ActionRollback {
+		t.Fatalf("Action() = %v, want ActionRollback (status %q)", m.Action(), m.status)
+	}
+	target := m.Selected()
+	if target.Value != "old" {
+		t.Fatalf("Selected() value = %q, want %q", target.Value, "old")
+	}
+
+	// The caller re-publishes the selected value as the new latest version
+	created, err := memory.SetSecret(ctx, "db", target.Value, nil)
+	if err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	got, err := memory.GetSecret(ctx, "db", "")
+	if err != nil || got.Version != created.Version || got.Value != "old" {
+		t.Errorf("latest after rollback = %v, %v; want %s with value %q", got, err, created.Version, "old")
+	}
+	versions, _ := memory.ListSecretVersions(ctx, "db")
+	if len(versions) != 3 || versions[1].Version != latest || versions[1].Enabled {
+		t.Errorf("versions = %+v, want the disabled version kept behind the rollback", versions)
+	}
+}
@@ -0,0 +1,274 @@
+package validate
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a validation failure at a position in the value. Line and
+// Column are 1-based; zero means the position is unknown.
+type Error struct {
+	Format string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("invalid %s at line %d, column %d: %s", e.Format, e.Line, e.Column, e.Msg)
+	case e.Line > 0:
+		return fmt.Sprintf("invalid %s at line %d: %s", e.Format, e.Line, e.Msg)
+	default:
+		return fmt.Sprintf("invalid %s: %s", e.Format, e.Msg)
+	}
+}
+
+// Validator checks that a value is well-formed, returning an *Error if not
+type Validator func(value string) error
+
+var validators = map[string]Validator{
+	"json": validateJSON,
+	"yaml": validateYAML,
+	"pem":  validatePEM,
+	"env":  validateEnv,
+}
+
+// contentTypes maps well-known content types to formats
+var contentTypes = map[string]string{
+	"application/json":       "json",
+	"text/json":              "json",
+	"application/yaml":       "yaml",
+	"application/x-yaml":     "yaml",
+	"text/yaml":              "yaml",
+	"text/x-yaml":            "yaml",
+	"application/x-pem-file": "pem",
+	"application/pem":        "pem",
+	"text/x-env":             "env",
+	"text/x-dotenv":          "env",
+}
+
+// Register adds or replaces the validator for a format
+func Register(format string, v Validator) {
+	validators[format] = v
+}
+
+// Formats returns the names of all registered formats
+func Formats() []string {
+	formats := make([]string, 0, len(validators))
+	for format := range validators {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Supported reports whether format can be passed to Validate
+func Supported(format string) bool {
+	if format == "" || format == "none" {
+		return true
+	}
+	_, ok := validators[format]
+	return ok
+}
+
+// ForContentType returns the format for a secret content type, or "" if
+// there is no validator for it. Format names themselves are accepted too.
+func ForContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	if format, ok := contentTypes[mediaType]; ok {
+		return format
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		return "json"
+	}
+	if strings.HasSuffix(mediaType, "+yaml") {
+		return "yaml"
+	}
+	if _, ok := validators[mediaType]; ok {
+		return mediaType
+	}
+	return ""
+}
+
+// Validate checks a value against a format. An empty format or "none"
+// always passes.
+func Validate(format, value string) error {
+	if format == "" || format == "none" {
+		return nil
+	}
+
+	v, ok := validators[format]
+	if !ok {
+		return fmt.Errorf("unknown format %q (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return v(value)
+}
+
+func validateJSON(value string) error {
+	var v any
+	err := json.Unmarshal([]byte(value), &v)
+	if err == nil {
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := position(value, max(int(syntaxErr.Offset)-1, 0))
+		return &Error{Format: "json", Line: line, Column: col, Msg: syntaxErr.Error()}
+	}
+	return &Error{Format: "json", Msg: err.Error()}
+}
+
+// yamlLine extracts the line number from yaml.v3 error messages
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+func validateYAML(value string) error {
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte(value), &node)
+	if err == nil {
+		return nil
+	}
+
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	line := 0
+	if m := yamlLine.FindStringSubmatch(msg); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		msg = strings.TrimPrefix(strings.TrimPrefix(msg, m[0]), ": ")
+	}
+	return &Error{Format: "yaml", Line: line, Msg: msg}
+}
+
+func validatePEM(value string) error {
+	rest := []byte(value)
+	blocks := 0
+
+	for {
+		offset := len(value) - len(rest)
+		block, next := pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		// pem.Decode skips anything before the block, including malformed
+		// blocks, so find where this one starts and check what it skipped
+		consumed := rest[:len(rest)-len(next)]
+		start := bytes.LastIndex(consumed, []byte("-----BEGIN"))
+		if err := checkOutsidePEM(value, offset, rest[:start]); err != nil {
+			return err
+		}
+		if err := checkPEMBlock(block); err != nil {
+			line, _ := position(value, offset+start)
+			return &Error{Format: "pem", Line: line, Msg: fmt.Sprintf("%s block: %v", block.Type, err)}
+		}
+
+		blocks++
+		rest = next
+	}
+
+	if err := checkOutsidePEM(value, len(value)-len(rest), rest); err != nil {
+		return err
+	}
+
+	if blocks == 0 {
+		return &Error{Format: "pem", Msg: "no PEM blocks found"}
+	}
+	return nil
+}
+
+// checkOutsidePEM rejects anything but whitespace in data, which sits at
+// offset in value between or around the PEM blocks
+func checkOutsidePEM(value string, offset int, data []byte) error {
+	trimmed := bytes.TrimLeftFunc(data, unicode.IsSpace)
+	if len(trimmed) == 0 {
+		return nil
+	}
+
+	line, col := position(value, offset+len(data)-len(trimmed))
+	msg := "unexpected data outside of a PEM block"
+	if bytes.HasPrefix(trimmed, []byte("-----BEGIN")) {
+		msg = "malformed PEM block (check the END line and base64 body)"
+	}
+	return &Error{Format: "pem", Line: line, Column: col, Msg: msg}
+}
+
+// checkPEMBlock parses the contents of well-known block types
+func checkPEMBlock(block *pem.Block) error {
+	var err error
+	switch block.Type {
+	case "CERTIFICATE":
+		_, err = x509.ParseCertificate(block.Bytes)
+	case "PRIVATE KEY":
+		_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		_, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		_, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		_, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return err
+}
+
+// envKey matches a valid environment variable name
+var envKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateEnv(value string) error {
+	for i, line := range strings.Split(value, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		entry := strings.TrimPrefix(trimmed, "export ")
+		if len(entry) != len(trimmed) {
+			indent += len("export ")
+		}
+
+		key, val, ok := strings.Cut(entry, "=")
+		if !ok {
+			return &Error{Format: "env", Line: i + 1, Column: indent + 1, Msg: "expected KEY=VALUE"}
+		}
+		if !envKey.MatchString(key) {
+			return &Error{Format: "env", Line: i + 1, Column: indent + 1, Msg: fmt.Sprintf("invalid variable name %q", key)}
+		}
+
+		// Quoted values must be closed on the same line
+		if len(val) > 0 && (val[0] == '"' || val[0] == '\'') {
+			quote := val[0]
+			if end := strings.IndexByte(val[1:], quote); end < 0 {
+				return &Error{Format: "env", Line: i + 1, Column: indent + len(key) + 2, Msg: "unterminated quoted value"}
+			}
+		}
+	}
+	return nil
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(value string, offset int) (int, int) {
+	if offset > len(value) {
+		offset = len(value)
+	}
+	before := value[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := offset - strings.LastIndex(before, "\n")
+	return line, col
+}
@@ -0,0 +1,126 @@
+package validate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+// ecKey returns a freshly generated EC private key in PEM form
+func ecKey(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+const testBlock = "-----BEGIN TEST-----\nAAAA\n-----END TEST-----\n"
+
+func TestValidate(t *testing.T) {
+	key := ecKey(t)
+
+	tests := []struct {
+		name     string
+		format   string
+		value    string
+		wantLine int
+		wantCol  int
+		wantErr  bool
+	}{
+		{name: "no format", format: "", value: "anything"},
+		{name: "none", format: "none", value: "{"},
+
+		{name: "json", format: "json", value: `{"a": [1, 2]}`},
+		{name: "json syntax error", format: "json", value: "{\n  \"a\": 1,\n}", wantErr: true, wantLine: 3, wantCol: 1},
+		{name: "json empty", format: "json", value: "", wantErr: true},
+
+		{name: "yaml", format: "yaml", value: "a: 1\nb: [x, y]\n"},
+		{name: "yaml error", format: "yaml", value: "a: 1\nb:\n\t- x\n", wantErr: true, wantLine: 3},
+
+		{name: "pem key", format: "pem", value: key},
+		{name: "pem bundle", format: "pem", value: key + "\n" + testBlock},
+		{name: "pem surrounding whitespace", format: "pem", value: "\n  \n" + testBlock + "\n\t\n"},
+		{name: "pem empty", format: "pem", value: "", wantErr: true},
+		{name: "pem no blocks", format: "pem", value: "just text", wantErr: true, wantLine: 1, wantCol: 1},
+		{name: "pem text before block", format: "pem", value: "\nsubject=CN=example\n" + testBlock, wantErr: true, wantLine: 2, wantCol: 1},
+		{name: "pem text between blocks", format: "pem", value: testBlock + "  junk\n" + testBlock, wantErr: true, wantLine: 4, wantCol: 3},
+		{name: "pem text after block", format: "pem", value: testBlock + "\ntrailer", wantErr: true, wantLine: 5, wantCol: 1},
+		{name: "pem malformed block skipped", format: "pem", value: "-----BEGIN TEST-----\n!!\n" + testBlock, wantErr: true, wantLine: 1, wantCol: 1},
+		{name: "pem truncated block", format: "pem", value: testBlock + "-----BEGIN TEST-----\nAAAA\n", wantErr: true, wantLine: 4, wantCol: 1},
+		{name: "pem corrupt certificate", format: "pem", value: "\n-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n", wantErr: true, wantLine: 2},
+
+		{name: "env", format: "env", value: "# comment\nA=1\nexport B=\"two words\"\n\n_C='x'"},
+		{name: "env missing equals", format: "env", value: "A=1\n  B", wantErr: true, wantLine: 2, wantCol: 3},
+		{name: "env bad name", format: "env", value: "1A=x", wantErr: true, wantLine: 1, wantCol: 1},
+		{name: "env unterminated quote", format: "env", value: "export KEY=\"open", wantErr: true, wantLine: 1, wantCol: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.format, tt.value)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+
+			var verr *Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate error = %v, want *Error", err)
+			}
+			if verr.Format != tt.format {
+				t.Errorf("Format = %q, want %q", verr.Format, tt.format)
+			}
+			if tt.wantLine != 0 && verr.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d (%v)", verr.Line, tt.wantLine, err)
+			}
+			if tt.wantCol != 0 && verr.Column != tt.wantCol {
+				t.Errorf("Column = %d, want %d (%v)", verr.Column, tt.wantCol, err)
+			}
+		})
+	}
+}
+
+func TestValidateUnknownFormat(t *testing.T) {
+	if err := Validate("toml", "a = 1"); err == nil {
+		t.Error("unknown format accepted")
+	}
+	if Supported("toml") {
+		t.Error("Supported(toml) = true")
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"application/json", "json"},
+		{"application/json; charset=utf-8", "json"},
+		{"Application/JSON", "json"},
+		{"application/vnd.api+json", "json"},
+		{"application/x-yaml", "yaml"},
+		{"application/ld+yaml", "yaml"},
+		{"application/x-pem-file", "pem"},
+		{"text/x-dotenv", "env"},
+		{"pem", "pem"},
+		{" env ", "env"},
+		{"text/plain", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ForContentType(tt.contentType); got != tt.want {
+			t.Errorf("ForContentType(%q) = %q, want %q", tt.contentType, got, tt.want)
+		}
+	}
+}
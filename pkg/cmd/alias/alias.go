@@ -0,0 +1,107 @@
+package alias
+
+import (
+	"fmt"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	kvconfig "github.com/bayhaqi/kv/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var AliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage secret and vault aliases",
+	Long: `Manage short names for secrets and vaults, stored in the kv config file.
+
+A secret alias names both the vault and the secret, so
+  kv alias add prod:db contoso-prod-kv sql-connection-string
+lets you run kv show prod:db or kv edit prod:db. A vault alias leaves out
+the secret and stands for the vault only:
+  kv alias add prod contoso-prod-kv
+  kv show prod db-password
+
+Aliases win over secrets and vaults with the same name.`,
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add <alias> <vault-name> [secret-name]",
+	Short: "Add or replace an alias",
+	Args:  cobra.RangeArgs(2, 3),
+	Run:   runAdd,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List aliases",
+	Args:  cobra.NoArgs,
+	Run:   runList,
+}
+
+var removeCmd = &cobra.Command{
+	Use:     "remove <alias>",
+	Aliases: []string{"rm"},
+	Short:   "Remove an alias",
+	Args:    cobra.ExactArgs(1),
+	Run:     runRemove,
+}
+
+func init() {
+	AliasCmd.AddCommand(addCmd, listCmd, removeCmd)
+	root.RootCmd.AddCommand(AliasCmd)
+}
+
+func runAdd(cmd *cobra.Command, args []string) {
+	cfg, err := kvconfig.Load()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	alias := kvconfig.Alias{Vault: args[1]}
+	if len(args) > 2 {
+		alias.Secret = args[2]
+	}
+
+	if err := cfg.AddAlias(args[0], alias); err != nil {
+		root.ExitWithError(err)
+	}
+	if err := cfg.Save(); err != nil {
+		root.ExitWithError(err)
+	}
+	fmt.Printf("✓ Alias '%s' → %s\n", args[0], alias)
+}
+
+func runList(cmd *cobra.Command, args []string) {
+	cfg, err := kvconfig.Load()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	names := cfg.AliasNames()
+	if len(names) == 0 {
+		fmt.Printf("No aliases in %s. Create one with: kv alias add <alias> <vault-name> [secret-name]\n", cfg.File())
+		return
+	}
+
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	for _, name := range names {
+		fmt.Printf("%-*s  %s\n", width, name, cfg.Aliases[name])
+	}
+}
+
+func runRemove(cmd *cobra.Command, args []string) {
+	cfg, err := kvconfig.Load()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	if err := cfg.RemoveAlias(args[0]); err != nil {
+		root.ExitWithError(err)
+	}
+	if err := cfg.Save(); err != nil {
+		root.ExitWithError(err)
+	}
+	fmt.Printf("✓ Removed alias '%s'\n", args[0])
+}
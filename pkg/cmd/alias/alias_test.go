@@ -0,0 +1,55 @@
+package alias
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/bayhaqi/kv/pkg/cmd/get"
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestAliasEndToEnd(t *testing.T) {
+	t.Setenv("KV_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	store := keyvault.NewMemoryStore()
+	if _, err := store.SetSecret(context.Background(), "db-password", "hunter2", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"alias", "add", "db", "prod-vault", "db-password"},
+		{"alias", "add", "prod", "prod-vault"},
+	} {
+		if res := roottest.Run(t, store, "", args...); res.ExitCode != 0 {
+			t.Fatalf("%v: exit code %d, stderr: %s", args, res.ExitCode, res.Stderr)
+		}
+	}
+
+	res := roottest.Run(t, store, "", "alias", "list")
+	if !strings.Contains(res.Stdout, "db    prod-vault/db-password") || !strings.Contains(res.Stdout, "prod  prod-vault") {
+		t.Errorf("alias list:\n%s", res.Stdout)
+	}
+
+	// Both kinds of alias open the aliased vault
+	for _, args := range [][]string{{"get", "db"}, {"get", "prod", "db-password"}} {
+		res := roottest.Run(t, store, "", args...)
+		if res.ExitCode != 0 || res.Stdout != "hunter2" {
+			t.Errorf("%v = %q (exit %d, stderr %s)", args, res.Stdout, res.ExitCode, res.Stderr)
+		}
+		if !strings.HasPrefix(res.VaultURL, "https://prod-vault.") {
+			t.Errorf("%v opened %s", args, res.VaultURL)
+		}
+	}
+
+	if res := roottest.Run(t, store, "", "alias", "rm", "prod"); res.ExitCode != 0 {
+		t.Fatalf("alias rm: exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if res := roottest.Run(t, store, "", "alias", "rm", "prod"); res.ExitCode == 0 {
+		t.Error("removing a missing alias succeeded")
+	}
+	if res := roottest.Run(t, store, "", "alias", "add", "bad/name", "prod-vault"); res.ExitCode == 0 {
+		t.Error("an alias name with a slash was accepted")
+	}
+}
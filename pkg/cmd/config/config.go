@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	kvconfig "github.com/bayhaqi/kv/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage settings and profiles",
+	Long: `Manage the kv config file and its named profiles.
+
+A profile holds the settings used when the matching flags are not passed:
+  ` + strings.Join(kvconfig.Keys(), ", ") + `
+
+With a default vault set, the vault argument can be left out, e.g.
+kv show db-password. Commands use the current profile unless --profile is
+given.`,
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting of the active profile",
+	Args:  cobra.ExactArgs(1),
+	Run:   runGet,
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting of the active profile (an empty value removes it)",
+	Args:  cobra.ExactArgs(2),
+	Run:   runSet,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles and their settings",
+	Args:  cobra.NoArgs,
+	Run:   runList,
+}
+
+var useCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Make a profile the current profile",
+	Args:  cobra.ExactArgs(1),
+	Run:   runUse,
+}
+
+func init() {
+	ConfigCmd.AddCommand(getCmd, setCmd, listCmd, useCmd)
+	root.RootCmd.AddCommand(ConfigCmd)
+}
+
+func runGet(cmd *cobra.Command, args []string) {
+	cfg, err := kvconfig.Load()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	value, err := cfg.Profile(root.ProfileName).Get(args[0])
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	fmt.Println(value)
+}
+
+func runSet(cmd *cobra.Command, args []string) {
+	cfg, err := kvconfig.Load()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	if err := cfg.Set(root.ProfileName, args[0], args[1]); err != nil {
+		root.ExitWithError(err)
+	}
+	if err := cfg.Save(); err != nil {
+		root.ExitWithError(err)
+	}
+	fmt.Printf("✓ Set %s for profile '%s' in %s\n", args[0], root.ProfileName, cfg.File())
+}
+
+func runList(cmd *cobra.Command, args []string) {
+	cfg, err := kvconfig.Load()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Printf("No profiles in %s. Create one with: kv config set vault <name>\n", cfg.File())
+		return
+	}
+
+	current := cfg.ActiveProfile("")
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+
+		profile := cfg.Profile(name)
+		for _, key := range kvconfig.Keys() {
+			if value, _ := profile.Get(key); value != "" {
+				fmt.Printf("    %s: %s\n", key, value)
+			}
+		}
+	}
+}
+
+func runUse(cmd *cobra.Command, args []string) {
+	cfg, err := kvconfig.Load()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	name := args[0]
+	if _, ok := cfg.Profiles[name]; !ok {
+		root.ExitWithError(fmt.Errorf("profile %q does not exist (create it with: kv config set vault <name> --profile %s)", name, name))
+	}
+
+	cfg.CurrentProfile = name
+	if err := cfg.Save(); err != nil {
+		root.ExitWithError(err)
+	}
+	fmt.Printf("✓ Now using profile '%s'\n", name)
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestSetAndGet(t *testing.T) {
+	t.Setenv("KV_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	store := keyvault.NewMemoryStore()
+
+	if res := roottest.Run(t, store, "", "config", "set", "vault", "my-vault"); res.ExitCode != 0 {
+		t.Fatalf("set: exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	res := roottest.Run(t, store, "", "config", "get", "vault")
+	if res.ExitCode != 0 || strings.TrimSpace(res.Stdout) != "my-vault" {
+		t.Errorf("get = %q (exit %d), want my-vault", res.Stdout, res.ExitCode)
+	}
+
+	if res := roottest.Run(t, store, "", "config", "set", "theme", "neon"); res.ExitCode == 0 {
+		t.Error("setting an unknown theme succeeded")
+	}
+}
+
+func TestUnknownThemeFallsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("KV_CONFIG", path)
+	if err := os.WriteFile(path, []byte("profiles:\n  default:\n    theme: neon\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store := keyvault.NewMemoryStore()
+
+	// Every command keeps working with a warning, including the fix
+	res := roottest.Run(t, store, "", "config", "set", "theme", "default")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, `unknown theme "neon"`) {
+		t.Errorf("stderr = %q, want a warning about the theme", res.Stderr)
+	}
+
+	res = roottest.Run(t, store, "", "config", "get", "theme")
+	if strings.TrimSpace(res.Stdout) != "default" || res.Stderr != "" {
+		t.Errorf("after the fix: stdout %q, stderr %q", res.Stdout, res.Stderr)
+	}
+}
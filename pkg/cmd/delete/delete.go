@@ -0,0 +1,104 @@
+package delete
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/spf13/cobra"
+)
+
+var (
+	yes     bool
+	confirm string
+)
+
+var DeleteCmd = &cobra.Command{
+	Use:   "delete [vault-name] <secret-name>",
+	Short: "Delete a secret and all its versions",
+	Long: `Delete a secret and all its versions from Azure Key Vault.
+
+With soft delete enabled on the vault, the secret can be listed with
+kv deleted list and restored with kv recover until its purge date. Without
+soft delete the secret is removed permanently, so its name has to be typed
+to confirm, or passed with --confirm.
+
+The command waits until Key Vault has finished deleting the secret.`,
+	Example: `  kv delete my-vault old-api-key
+  kv delete my-vault old-api-key --yes`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runDelete,
+}
+
+func init() {
+	DeleteCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking when the secret can be recovered")
+	DeleteCmd.Flags().StringVar(&confirm, "confirm", "", "Secret name, to confirm a permanent delete without a prompt")
+	root.RootCmd.AddCommand(DeleteCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string) {
+	vaultName, args, err := root.ResolveTarget(cmd, args, 2)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	ok, err := confirmDelete(ctx, client, secretName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	if !ok {
+		fmt.Fprintln(root.Progress(), "Aborted. Secret not deleted.")
+		return
+	}
+
+	fmt.Fprintf(root.Progress(), "Deleting '%s'...\n", secretName)
+	deleted, err := client.DeleteSecret(ctx, secretName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	if !keyvault.Recoverable(deleted.RecoveryLevel) {
+		fmt.Fprintf(root.Progress(), "✓ Secret '%s' permanently deleted\n", secretName)
+		return
+	}
+
+	purgeDate := "its purge date"
+	if deleted.ScheduledPurgeDate != nil {
+		purgeDate = deleted.ScheduledPurgeDate.Local().Format("2006-01-02 15:04")
+	}
+	fmt.Fprintf(root.Progress(), "✓ Secret '%s' deleted. Recover it with 'kv recover %s %s' until %s.\n", secretName, vaultName, secretName, purgeDate)
+}
+
+// confirmDelete asks before deleting. Recoverable secrets need a yes/no
+// answer or --yes; permanent deletes need the name typed or --confirm.
+func confirmDelete(ctx context.Context, store keyvault.SecretStore, secretName string) (bool, error) {
+	versions, err := store.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		return false, err
+	}
+	if len(versions) == 0 {
+		return false, fmt.Errorf("%w: %s", keyvault.ErrSecretNotFound, secretName)
+	}
+
+	if !keyvault.Recoverable(versions[0].RecoveryLevel) {
+		question := fmt.Sprintf("Soft delete is disabled on this vault: deleting '%s' removes its %d version(s) permanently.", secretName, len(versions))
+		return root.ConfirmTyped(question, secretName, confirm)
+	}
+
+	if yes {
+		return true, nil
+	}
+	if !root.IsTerminal(os.Stdin) {
+		return false, fmt.Errorf("no terminal attached to confirm: re-run with --yes to delete '%s'", secretName)
+	}
+	return root.Confirm(fmt.Sprintf("Delete '%s' and its %d version(s)?", secretName, len(versions)), false), nil
+}
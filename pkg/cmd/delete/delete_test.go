@@ -0,0 +1,103 @@
+package delete
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name          string
+		recoveryLevel string
+		secret        string
+		args          []string
+		wantExit      int
+		wantStderr    string
+		wantDeleted   bool
+		wantRecovery  bool
+	}{
+		{
+			name:          "recoverable without a terminal",
+			recoveryLevel: "Recoverable+Purgeable",
+			secret:        "db",
+			wantExit:      1,
+			wantStderr:    "re-run with --yes",
+		},
+		{
+			name:          "recoverable with --yes",
+			recoveryLevel: "Recoverable+Purgeable",
+			secret:        "db",
+			args:          []string{"--yes"},
+			wantDeleted:   true,
+			wantRecovery:  true,
+		},
+		{
+			name:          "permanent with --yes only",
+			recoveryLevel: "Purgeable",
+			secret:        "db",
+			args:          []string{"--yes"},
+			wantExit:      1,
+			wantStderr:    "re-run with --confirm db",
+		},
+		{
+			name:          "permanent with the wrong name",
+			recoveryLevel: "Purgeable",
+			secret:        "db",
+			args:          []string{"--confirm", "db2"},
+			wantExit:      1,
+			wantStderr:    `--confirm "db2" does not match 'db'`,
+		},
+		{
+			name:          "permanent with the name",
+			recoveryLevel: "Purgeable",
+			secret:        "db",
+			args:          []string{"--confirm", "db"},
+			wantDeleted:   true,
+		},
+		{
+			name:          "missing secret",
+			recoveryLevel: "Recoverable+Purgeable",
+			secret:        "missing",
+			args:          []string{"--yes", "--confirm", "missing"},
+			wantExit:      2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := keyvault.NewMemoryStore()
+			store.RecoveryLevel = tt.recoveryLevel
+			if _, err := store.SetSecret(ctx, "db", "v", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			res := roottest.Run(t, store, "", append([]string{"delete", "vault", tt.secret}, tt.args...)...)
+			if res.ExitCode != tt.wantExit {
+				t.Fatalf("exit code %d, want %d (stderr: %s)", res.ExitCode, tt.wantExit, res.Stderr)
+			}
+			if !strings.Contains(res.Stderr, tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", res.Stderr, tt.wantStderr)
+			}
+
+			_, err := store.GetSecret(ctx, "db", "")
+			if deleted := errors.Is(err, keyvault.ErrSecretNotFound); deleted != tt.wantDeleted {
+				t.Errorf("deleted = %t (GetSecret: %v), want %t", deleted, err, tt.wantDeleted)
+			}
+			listed, err := store.ListDeletedSecrets(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recoverable := len(listed) == 1; recoverable != tt.wantRecovery {
+				t.Errorf("deleted secrets = %+v, want recoverable: %t", listed, tt.wantRecovery)
+			}
+			if tt.wantRecovery && !strings.Contains(res.Stdout, "kv recover vault db") {
+				t.Errorf("stdout = %q, want it to say how to recover", res.Stdout)
+			}
+		})
+	}
+}
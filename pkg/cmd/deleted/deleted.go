@@ -0,0 +1,67 @@
+package deleted
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+var DeletedCmd = &cobra.Command{
+	Use:   "deleted",
+	Short: "Manage soft-deleted secrets",
+	Long: `Manage secrets that were deleted from a vault with soft delete enabled.
+
+Deleted secrets can be restored with kv recover or removed for good with
+kv purge until their scheduled purge date.`,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list [vault-name]",
+	Short: "List soft-deleted secrets",
+	Long: `List the soft-deleted secrets in a vault with their deletion and scheduled
+purge dates.
+
+Use --output json or yaml for scripts; the default is a table.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runList,
+}
+
+func init() {
+	DeletedCmd.AddCommand(listCmd)
+	root.RootCmd.AddCommand(DeletedCmd)
+}
+
+func runList(cmd *cobra.Command, args []string) {
+	vaultName, _, err := root.ResolveTarget(cmd, args, 1)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	secrets, err := client.ListDeletedSecrets(ctx)
+	if err != nil {
+		root.ExitWithError(fmt.Errorf("failed to list deleted secrets: %w", err))
+	}
+
+	format := root.Output
+	if format == "" {
+		if len(secrets) == 0 {
+			fmt.Println("No deleted secrets in this vault.")
+			return
+		}
+		format = output.Table
+	}
+
+	if err := output.WriteDeleted(os.Stdout, format, output.FromDeleted(secrets)); err != nil {
+		root.ExitWithError(fmt.Errorf("failed to write output: %w", err))
+	}
+}
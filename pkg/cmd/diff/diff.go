@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bayhaqi/kv/internal/textdiff"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/spf13/cobra"
+)
+
+var contextLines int
+
+var DiffCmd = &cobra.Command{
+	Use:   "diff [vault-name] <secret-name> <version1> <version2>",
+	Short: "Print a unified diff between two secret versions",
+	Long: `Print a unified diff between two versions of a secret.
+
+Versions can be given as full IDs, unique prefixes, or "latest". Nothing is
+printed when the values are identical.`,
+	Args: cobra.RangeArgs(3, 4),
+	Run:  runDiff,
+}
+
+func init() {
+	DiffCmd.Flags().IntVarP(&contextLines, "context", "U", 3, "Number of context lines")
+	root.RootCmd.AddCommand(DiffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	vaultName, args, err := root.ResolveTarget(cmd, args, 4)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	versions, err := client.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		root.ExitWithError(fmt.Errorf("failed to list secret versions: %w", err))
+	}
+
+	oldVersion, err := getVersion(ctx, client, secretName, versions, args[1])
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	newVersion, err := getVersion(ctx, client, secretName, versions, args[2])
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	fmt.Print(textdiff.Unified(
+		fmt.Sprintf("%s@%s", secretName, keyvault.ShortVersion(oldVersion.Version)),
+		fmt.Sprintf("%s@%s", secretName, keyvault.ShortVersion(newVersion.Version)),
+		oldVersion.Value,
+		newVersion.Value,
+		contextLines,
+	))
+}
+
+// getVersion resolves a version ID, prefix or "latest" and fetches its value
+func getVersion(ctx context.Context, store keyvault.SecretStore, secretName string, versions []keyvault.SecretVersion, id string) (*keyvault.SecretVersion, error) {
+	if id == "latest" {
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("%w: %s", keyvault.ErrSecretNotFound, secretName)
+		}
+		id = versions[0].Version
+	}
+
+	version, err := keyvault.FindVersion(versions, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := store.GetSecret(ctx, secretName, version.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version %s: %w", keyvault.ShortVersion(version.Version), err)
+	}
+	return secret, nil
+}
@@ -5,11 +5,16 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/bayhaqi/kv/internal/difftui"
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/internal/textdiff"
+	"github.com/bayhaqi/kv/internal/validate"
 	"github.com/bayhaqi/kv/pkg/cmd/root"
 	"github.com/bayhaqi/kv/pkg/keyvault"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,150 +25,392 @@ var (
 	editor         string
 	skipValidation bool
 	fromFile       string
+	force          bool
+	format         string
+	allowDiskTemp  bool
+	withMetadata   bool
+	yes            bool
 )
 
 var EditCmd = &cobra.Command{
-	Use:   "edit <vault-name> <secret-name>",
+	Use:   "edit [vault-name] <secret-name>",
 	Short: "Edit a secret in Azure Key Vault",
-	Long:  `Edit the latest version of a secret in Azure Key Vault using your preferred editor.`,
-	Args:  cobra.ExactArgs(2),
-	Run:   runEdit,
+	Long: `Edit the latest version of a secret in Azure Key Vault using your preferred editor.
+
+The edited value is checked before the diff is shown when the secret's
+content type (or --format) is one of: json, yaml, pem, env. Use
+--format none to skip the check.
+
+With --with-metadata the file starts with a YAML header holding the content
+type, enabled state, activation and expiry dates and tags. Changing only the
+header updates the current version in place; changing the value writes a
+new version.
+
+The new value can be piped in (or read with --file -). Without a terminal
+on stdin the changes are printed as a unified diff instead of the review
+screen, and --yes is required to write them. The editor and review screen
+are only used when both stdin and stdout are terminals.`,
+	Annotations: map[string]string{root.ValuesAnnotation: root.ValuesWithReveal},
+	Args:        cobra.RangeArgs(1, 2),
+	Run:         runEdit,
 }
 
 func init() {
-	EditCmd.Flags().StringVarP(&editor, "editor", "e", "", "Editor to use (default: $EDITOR or vim)")
+	EditCmd.Flags().StringVarP(&editor, "editor", "e", "", "Editor command, may include arguments (default: profile editor, $VISUAL, $EDITOR or vim)")
 	EditCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip the diff confirmation step")
-	EditCmd.Flags().StringVarP(&fromFile, "file", "f", "", "Read secret value from file instead of opening editor")
+	EditCmd.Flags().StringVarP(&fromFile, "file", "f", "", "Read secret value from file (- for stdin) instead of opening editor")
+	EditCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Write the changes without review when stdin is not a terminal")
+	EditCmd.Flags().BoolVar(&force, "force", false, "Overwrite even if the secret was updated while editing")
+	EditCmd.Flags().BoolVar(&allowDiskTemp, "allow-disk-temp", false, "Allow the edit buffer on a disk-backed temp directory when no memory-backed one is available")
+	EditCmd.Flags().BoolVar(&withMetadata, "with-metadata", false, "Also edit content type, enabled state, dates and tags in a YAML header")
+	EditCmd.Flags().StringVar(&format, "format", "", "Validate the value as "+strings.Join(validate.Formats(), "|")+" or none (default: from content type)")
 	root.RootCmd.AddCommand(EditCmd)
 }
 
 func runEdit(cmd *cobra.Command, args []string) {
-	vaultName := args[0]
-	secretName := args[1]
-
-	// Build vault URL from vault name
-	vaultURL := fmt.Sprintf("https://%s.vault.azure.net/", vaultName)
+	vaultName, args, err := root.ResolveTarget(cmd, args, 2)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
 
-	// Fetch secret versions
 	ctx := context.Background()
-	client, err := keyvault.NewClient(vaultURL)
+	client, err := root.OpenStore(vaultName)
 	if err != nil {
-		root.ExitWithError(fmt.Errorf("failed to create Key Vault client: %w", err))
+		root.ExitWithError(err)
 	}
 
-	versions, err := client.ListSecretVersions(ctx, secretName)
+	updated, err := EditSecret(ctx, client, secretName)
 	if err != nil {
-		root.ExitWithError(fmt.Errorf("failed to list secret versions: %w", err))
+		root.ExitWithError(err)
+	}
+	if updated != nil && root.Output != "" {
+		if err := output.WriteOne(os.Stdout, root.Output, output.FromVersion(secretName, *updated, root.Reveal)); err != nil {
+			root.ExitWithError(fmt.Errorf("failed to write output: %w", err))
+		}
+	}
+}
+
+// EditSecret edits the latest version of a secret and writes the result as
+// a new version once the user confirms the diff. It returns the written
+// version, or nil if nothing was written.
+func EditSecret(ctx context.Context, store keyvault.SecretStore, secretName string) (*keyvault.SecretVersion, error) {
+	// Fetch the latest version including its value. A disabled latest
+	// version can't be read, so editing it starts from an empty value.
+	latestVersion, err := keyvault.LatestVersion(ctx, store, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest version: %w", err)
+	}
+	if latestVersion.ValueErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: the latest version %s is disabled; editing starts from an empty value\n", keyvault.ShortVersion(latestVersion.Version))
+	}
+
+	valueFormat := format
+	if valueFormat == "" {
+		valueFormat = validate.ForContentType(latestVersion.ContentType)
+	}
+	if !validate.Supported(valueFormat) {
+		return nil, fmt.Errorf("unknown format %q (available: %s, none)", valueFormat, strings.Join(validate.Formats(), ", "))
 	}
 
-	if len(versions) == 0 {
-		root.ExitWithError(fmt.Errorf("no versions found for secret: %s", secretName))
+	// The text being edited is the value, or a header and the value with
+	// --with-metadata
+	original := latestVersion.Value
+	if withMetadata {
+		original, err = renderDocument(latestVersion)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Get the latest version (first in the list)
-	latestVersion := versions[0]
+	var edited, newValueStr, tempFile, editorCmd string
+	var newMeta metadata
 
-	var newValueStr string
+	// Without a terminal there is nobody to answer the editor or the review
+	// screen, so the value has to come from a flag or stdin
+	fromStdin := fromFile == "-" || (fromFile == "" && !root.IsTerminal(os.Stdin))
+	interactive := !fromStdin && root.Interactive()
+	if fromFile == "" && !fromStdin && !interactive {
+		return nil, fmt.Errorf("stdout is not a terminal: pass the new value with --file or on stdin")
+	}
 
-	// Check if reading from file
-	if fromFile != "" {
+	// Check if reading from stdin or a file
+	if fromStdin {
+		fmt.Fprintln(root.Progress(), "Reading secret value from stdin")
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		// An empty stdin that wasn't asked for is more likely a missing
+		// pipe than a request to blank the secret
+		if len(content) == 0 && fromFile != "-" {
+			return nil, fmt.Errorf("no terminal attached and stdin is empty: pass the new value with --file or on stdin")
+		}
+		edited = string(content)
+	} else if fromFile != "" {
 		// Validate that file path is absolute or clean it
 		cleanPath := filepath.Clean(fromFile)
-		fmt.Printf("Reading secret value from file: %s\n", cleanPath)
+		fmt.Fprintf(root.Progress(), "Reading secret value from file: %s\n", cleanPath)
 		content, err := os.ReadFile(cleanPath) // #nosec G304 - User-specified file path for reading secret
 		if err != nil {
-			root.ExitWithError(fmt.Errorf("failed to read file: %w", err))
+			return nil, fmt.Errorf("failed to read file: %w", err)
 		}
-		newValueStr = string(content)
+		edited = string(content)
 	} else {
 		// Determine editor
-		editorCmd := getEditor()
-
-		// Create secure temporary file
-		tempFile, err := createSecureTempFile(latestVersion.Value)
+		editorCmd = getEditor()
+
+		// Create the edit buffer, in memory unless disk is allowed
+		// With --with-metadata the buffer starts with a YAML header, which
+		// is what the editor should highlight
+		ext := tempFileExt(valueFormat)
+		switch {
+		case withMetadata:
+			ext = tempFileExt("yaml")
+		case valueFormat == "none":
+			ext = tempFileExt(validate.ForContentType(latestVersion.ContentType))
+		}
+		buffer, err := newEditBuffer(original, ext, allowDiskTemp)
 		if err != nil {
-			root.ExitWithError(fmt.Errorf("failed to create temporary file: %w", err))
+			return nil, err
 		}
+		tempFile = buffer.path
 		defer func() {
 			// Securely delete the temporary file
-			if err := secureDelete(tempFile); err != nil {
+			if err := buffer.release(); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to securely delete temp file: %v\n", err)
 			}
 		}()
 
-		fmt.Printf("Editing secret '%s' (version: %s)\n", secretName, latestVersion.Version[:8])
-		fmt.Printf("Opening editor: %s\n\n", editorCmd)
+		fmt.Fprintf(root.Progress(), "Editing secret '%s' (version: %s)\n", secretName, keyvault.ShortVersion(latestVersion.Version))
+		if buffer.inMemory {
+			fmt.Fprintf(root.Progress(), "Temporary file: %s (memory-backed)\n", buffer.location)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: temporary file is in %s, which may be disk-backed\n", buffer.location)
+		}
+	}
+
+	// Keep going back to the same temp file until the user confirms or
+	// gives up, so a mistake doesn't cost the whole edit
+	for {
+		if tempFile != "" {
+			fmt.Fprintf(root.Progress(), "Opening editor: %s\n\n", editorCmd)
+
+			// Open editor
+			if err := openEditor(editorCmd, tempFile); err != nil {
+				return nil, fmt.Errorf("failed to open editor: %w", err)
+			}
 
-		// Open editor
-		if err := openEditor(editorCmd, tempFile); err != nil {
-			root.ExitWithError(fmt.Errorf("failed to open editor: %w", err))
+			// Read the edited content
+			newValue, err := os.ReadFile(tempFile) // #nosec G304 - Reading from controlled temp file we created
+			if err != nil {
+				return nil, fmt.Errorf("failed to read edited file: %w", err)
+			}
+			edited = string(newValue)
 		}
 
-		// Read the edited content
-		newValue, err := os.ReadFile(tempFile) // #nosec G304 - Reading from controlled temp file we created
+		// Check if content was changed
+		if edited == original {
+			fmt.Fprintln(root.Progress(), "No changes detected. Secret not updated.")
+			return nil, nil
+		}
+
+		newMeta, newValueStr, err = parseEdited(edited, valueFormat)
 		if err != nil {
-			root.ExitWithError(fmt.Errorf("failed to read edited file: %w", err))
+			if tempFile == "" {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			if !root.Confirm("Reopen the editor to fix it?", true) {
+				fmt.Fprintln(root.Progress(), "Changes discarded.")
+				return nil, nil
+			}
+			continue
 		}
 
-		newValueStr = string(newValue)
-	}
+		if skipValidation {
+			fmt.Fprintln(root.Progress(), "\nSkipping validation...")
+			break
+		}
 
-	// Check if content was changed
-	if newValueStr == latestVersion.Value {
-		fmt.Println("No changes detected. Secret not updated.")
-		return
-	}
+		if !interactive {
+			fmt.Fprint(root.Progress(), textdiff.Unified(
+				fmt.Sprintf("%s@%s", secretName, keyvault.ShortVersion(latestVersion.Version)),
+				fmt.Sprintf("%s (edited)", secretName),
+				original, edited, 3))
+			if !yes {
+				return nil, fmt.Errorf("no terminal attached: review the diff above and re-run with --yes to update '%s'", secretName)
+			}
+			break
+		}
 
-	// Show diff in TUI for confirmation unless skipped
-	if !skipValidation {
-		fmt.Println("\nReview changes...")
-		diffModel := difftui.NewModel(latestVersion.Value, newValueStr, secretName)
+		// Show diff in TUI for confirmation
+		fmt.Fprintln(root.Progress(), "\nReview changes...")
+		diffModel := difftui.NewModel(original, edited, secretName)
+		if tempFile != "" {
+			diffModel = difftui.NewEditModel(original, edited, secretName)
+		}
 		p := tea.NewProgram(diffModel, tea.WithAltScreen())
 
 		finalModel, err := p.Run()
 		if err != nil {
-			root.ExitWithError(fmt.Errorf("diff viewer error: %w", err))
+			return nil, fmt.Errorf("diff viewer error: %w", err)
 		}
 
 		diffResult := finalModel.(difftui.Model)
+		if diffResult.EditRequested() {
+			continue
+		}
 		if !diffResult.Confirmed() {
-			fmt.Println("Changes discarded.")
-			return
+			fmt.Fprintln(root.Progress(), "Changes discarded.")
+			return nil, nil
 		}
-	} else {
-		fmt.Println("\nSkipping validation...")
+		break
 	}
 
-	// Update the secret in Key Vault
-	if err := client.SetSecret(ctx, secretName, newValueStr); err != nil {
-		root.ExitWithError(fmt.Errorf("failed to update secret: %w", err))
+	// Make sure nobody updated the secret while it was being edited
+	current, err := keyvault.LatestVersion(ctx, store, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-read latest version: %w", err)
+	}
+	if current.Version != latestVersion.Version && !force {
+		overwrite, err := resolveConflict(secretName, latestVersion, current, original, edited, interactive)
+		if err != nil {
+			return nil, err
+		}
+		if !overwrite {
+			fmt.Fprintf(root.Progress(), "Edit aborted: '%s' is now at version %s. Secret not updated.\n",
+				secretName, keyvault.ShortVersion(current.Version))
+			return nil, nil
+		}
+	}
+
+	if withMetadata {
+		return writeWithMetadata(ctx, store, secretName, latestVersion, current, newMeta, newValueStr)
+	}
+
+	// Update the secret in Key Vault, carrying over the content type and tags
+	// since they describe the secret rather than a single value
+	attrs := &keyvault.SecretAttributes{
+		Tags: current.Tags,
+	}
+	if current.ContentType != "" {
+		attrs.ContentType = &current.ContentType
+	}
+	created, err := store.SetSecret(ctx, secretName, newValueStr, attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update secret: %w", err)
+	}
+
+	fmt.Fprintf(root.Progress(), "✓ Secret '%s' updated successfully\n", secretName)
+	return created, nil
+}
+
+// parseEdited splits the edited text into header and value when editing
+// metadata, then validates the value
+func parseEdited(edited, valueFormat string) (metadata, string, error) {
+	var meta metadata
+	value := edited
+	if withMetadata {
+		var err error
+		if meta, value, err = parseDocument(edited); err != nil {
+			return meta, "", err
+		}
+	}
+	return meta, value, validate.Validate(valueFormat, value)
+}
+
+// writeWithMetadata saves a --with-metadata edit. A new version is needed
+// when the value changed, when a date was cleared or when overwriting a
+// newer version; otherwise the edited version is updated in place.
+func writeWithMetadata(ctx context.Context, store keyvault.SecretStore, secretName string, base, current *keyvault.SecretVersion, meta metadata, value string) (*keyvault.SecretVersion, error) {
+	attrs := meta.attributes()
+
+	if value == base.Value && current.Version == base.Version && !meta.clearsDates(metadataOf(base)) {
+		updated, err := store.UpdateSecretProperties(ctx, secretName, base.Version, attrs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update secret properties: %w", err)
+		}
+		fmt.Fprintf(root.Progress(), "✓ Secret '%s' properties updated (version %s)\n", secretName, keyvault.ShortVersion(base.Version))
+		return updated, nil
+	}
+
+	created, err := store.SetSecret(ctx, secretName, value, &attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update secret: %w", err)
+	}
+	fmt.Fprintf(root.Progress(), "✓ Secret '%s' updated successfully\n", secretName)
+	return created, nil
+}
+
+// resolveConflict asks the user whether to overwrite a version written by
+// someone else during the edit. Without the interactive diff it refuses.
+func resolveConflict(secretName string, base, theirs *keyvault.SecretVersion, baseText, mine string, interactive bool) (bool, error) {
+	if skipValidation || !interactive {
+		return false, fmt.Errorf("secret '%s' was updated while editing (version %s, edit started from %s); re-run the edit or pass --force to overwrite",
+			secretName, keyvault.ShortVersion(theirs.Version), keyvault.ShortVersion(base.Version))
+	}
+
+	theirText := theirs.Value
+	if withMetadata {
+		var err error
+		if theirText, err = renderDocument(theirs); err != nil {
+			return false, err
+		}
+	}
+
+	conflictModel := difftui.NewConflictModel(baseText, theirText, mine, secretName,
+		keyvault.ShortVersion(base.Version), keyvault.ShortVersion(theirs.Version))
+	p := tea.NewProgram(conflictModel, tea.WithAltScreen())
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return false, fmt.Errorf("conflict viewer error: %w", err)
 	}
 
-	fmt.Printf("✓ Secret '%s' updated successfully\n", secretName)
+	return finalModel.(difftui.ConflictModel).Resolution() == difftui.ResolutionOverwrite, nil
 }
 
+// getEditor returns the editor command line: --editor, then the profile's
+// editor, then $VISUAL, then $EDITOR, then vim
 func getEditor() string {
 	if editor != "" {
 		return editor
 	}
 
-	if env := os.Getenv("EDITOR"); env != "" {
-		return env
+	if root.Profile.Editor != "" {
+		return root.Profile.Editor
+	}
+
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if env := os.Getenv(name); env != "" {
+			return env
+		}
 	}
 
 	return "vim"
 }
 
-func createSecureTempFile(content string) (string, error) {
+// tempFileExt returns the temp file extension for a value format, so the
+// editor picks a matching syntax mode
+func tempFileExt(valueFormat string) string {
+	switch valueFormat {
+	case "json", "yaml", "pem", "env":
+		return "." + valueFormat
+	default:
+		return ".tmp"
+	}
+}
+
+func createSecureTempFile(tempDir, content, ext string) (string, error) {
 	// Generate random filename
 	randomBytes := make([]byte, 16)
 	if _, err := rand.Read(randomBytes); err != nil {
 		return "", err
 	}
-	filename := "kv-secret-" + hex.EncodeToString(randomBytes) + ".tmp"
+	filename := "kv-secret-" + hex.EncodeToString(randomBytes) + ext
 
 	// Create temp file with restricted permissions (0600 - owner read/write only)
-	tempDir := os.TempDir()
 	tempPath := filepath.Join(tempDir, filename)
 
 	file, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600) // #nosec G304 - Creating temp file in system temp dir with random name
@@ -187,7 +434,12 @@ func createSecureTempFile(content string) (string, error) {
 }
 
 func openEditor(editorCmd, filePath string) error {
-	cmd := exec.Command(editorCmd, filePath)
+	args, err := editorArgs(editorCmd)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], filePath)...) // #nosec G204 - Editor chosen by the user
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -0,0 +1,245 @@
+package edit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+// newStore returns a store holding one JSON version of "config"
+func newStore(t *testing.T) (*keyvault.MemoryStore, *keyvault.SecretVersion) {
+	t.Helper()
+
+	store := keyvault.NewMemoryStore()
+	contentType := "application/json"
+	v, err := store.SetSecret(context.Background(), "config", `{"a": 1}`, &keyvault.SecretAttributes{
+		ContentType: &contentType,
+		Tags:        map[string]string{"owner": "payments"},
+	})
+	if err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	return store, v
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "value")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func latest(t *testing.T, store keyvault.SecretStore) *keyvault.SecretVersion {
+	t.Helper()
+	v, err := store.GetSecret(context.Background(), "config", "")
+	if err != nil {
+		t.Fatalf("GetSecret: %v", err)
+	}
+	return v
+}
+
+func TestEditFromFile(t *testing.T) {
+	store, base := newStore(t)
+	path := writeFile(t, `{"a": 2}`)
+
+	res := roottest.Run(t, store, "", "edit", "my-vault", "config", "--file", path, "--yes")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, `-{"a": 1}`) || !strings.Contains(res.Stdout, `+{"a": 2}`) {
+		t.Errorf("unified diff missing from output:\n%s", res.Stdout)
+	}
+
+	got := latest(t, store)
+	if got.Version == base.Version || got.Value != `{"a": 2}` {
+		t.Fatalf("latest = %s %q, want a new version with the edited value", got.Version, got.Value)
+	}
+}
+
+func TestEditKeepsTagsAndContentType(t *testing.T) {
+	store, base := newStore(t)
+
+	// The tags of the latest version are carried over, not the ones the
+	// secret was created with
+	if _, err := store.UpdateSecretProperties(context.Background(), "config", base.Version, keyvault.SecretAttributes{
+		Tags: map[string]string{"owner": "billing", "env": "prod"},
+	}); err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+
+	res := roottest.Run(t, store, `{"a": 2}`, "edit", "my-vault", "config", "--yes")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+
+	got := latest(t, store)
+	if got.Version == base.Version {
+		t.Fatal("no new version was written")
+	}
+	if got.ContentType != "application/json" {
+		t.Errorf("content type = %q, want application/json", got.ContentType)
+	}
+	if len(got.Tags) != 2 || got.Tags["owner"] != "billing" || got.Tags["env"] != "prod" {
+		t.Errorf("tags = %v, want map[env:prod owner:billing]", got.Tags)
+	}
+}
+
+func TestEditFromStdin(t *testing.T) {
+	store, _ := newStore(t)
+
+	res := roottest.Run(t, store, `{"a": 3}`, "edit", "my-vault", "config", "--yes")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if got := latest(t, store).Value; got != `{"a": 3}` {
+		t.Errorf("value = %q", got)
+	}
+}
+
+func TestEditRefusesWithoutConfirmation(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		wantErr string
+	}{
+		{"no --yes", `{"a": 2}`, nil, "re-run with --yes"},
+		{"invalid json", `{"a": }`, []string{"--yes"}, "invalid json"},
+		{"empty implicit stdin", "", []string{"--yes"}, "stdin is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, base := newStore(t)
+
+			args := append([]string{"edit", "my-vault", "config"}, tt.args...)
+			res := roottest.Run(t, store, tt.stdin, args...)
+			if res.ExitCode == 0 {
+				t.Fatalf("edit succeeded, want an error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(res.Stderr, tt.wantErr) {
+				t.Errorf("stderr = %q, want it to contain %q", res.Stderr, tt.wantErr)
+			}
+			if got := latest(t, store); got.Version != base.Version {
+				t.Errorf("a new version %s was written", got.Version)
+			}
+		})
+	}
+}
+
+func TestEditNoChanges(t *testing.T) {
+	store, base := newStore(t)
+	path := writeFile(t, `{"a": 1}`)
+
+	res := roottest.Run(t, store, "", "edit", "my-vault", "config", "--file", path, "--yes")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "No changes detected") {
+		t.Errorf("stdout = %q", res.Stdout)
+	}
+	if got := latest(t, store); got.Version != base.Version {
+		t.Errorf("a new version %s was written", got.Version)
+	}
+}
+
+// racingStore writes a new version right after the edit first reads the
+// secret, like a teammate saving while the editor is open
+type racingStore struct {
+	*keyvault.MemoryStore
+	raced bool
+}
+
+func (s *racingStore) GetSecret(ctx context.Context, name, version string) (*keyvault.SecretVersion, error) {
+	v, err := s.MemoryStore.GetSecret(ctx, name, version)
+	if err == nil && !s.raced {
+		s.raced = true
+		if _, err := s.MemoryStore.SetSecret(ctx, name, `{"a": "theirs"}`, nil); err != nil {
+			return nil, err
+		}
+	}
+	return v, err
+}
+
+func TestEditDetectsConcurrentUpdate(t *testing.T) {
+	memory, _ := newStore(t)
+	store := &racingStore{MemoryStore: memory}
+	path := writeFile(t, `{"a": "mine"}`)
+
+	res := roottest.Run(t, store, "", "edit", "my-vault", "config", "--file", path, "--yes")
+	if res.ExitCode == 0 || !strings.Contains(res.Stderr, "was updated while editing") {
+		t.Fatalf("exit code %d, stderr %q; want a conflict error", res.ExitCode, res.Stderr)
+	}
+	if got := latest(t, memory).Value; got != `{"a": "theirs"}` {
+		t.Errorf("value = %q, want the concurrent update kept", got)
+	}
+
+	store.raced = false
+	res = roottest.Run(t, store, "", "edit", "my-vault", "config", "--file", path, "--yes", "--force")
+	if res.ExitCode != 0 {
+		t.Fatalf("--force: exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if got := latest(t, memory).Value; got != `{"a": "mine"}` {
+		t.Errorf("value = %q, want the edit written with --force", got)
+	}
+}
+
+func TestEditDisabledLatest(t *testing.T) {
+	store, base := newStore(t)
+	off := false
+	if _, err := store.UpdateSecretProperties(context.Background(), "config", base.Version, keyvault.SecretAttributes{Enabled: &off}); err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+
+	res := roottest.Run(t, store, `{"a": 2}`, "edit", "my-vault", "config", "--yes")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stderr, "is disabled") || !strings.Contains(res.Stdout, `+{"a": 2}`) {
+		t.Errorf("stdout:\n%s\nstderr:\n%s", res.Stdout, res.Stderr)
+	}
+
+	got := latest(t, store)
+	if got.Version == base.Version || got.Value != `{"a": 2}` || got.Tags["owner"] != "payments" {
+		t.Errorf("latest = %s %q %v, want a new enabled version with the edited value", got.Version, got.Value, got.Tags)
+	}
+}
+
+func TestEditWithMetadataEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantEnabled bool
+	}{
+		{"enabled left out", "contentType: application/json\n", true},
+		{"enabled false", "contentType: application/json\nenabled: false\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, base := newStore(t)
+			path := writeFile(t, "---\n"+tt.header+"---\n"+base.Value)
+
+			res := roottest.Run(t, store, "", "edit", "my-vault", "config", "--with-metadata", "--file", path, "--yes")
+			if res.ExitCode != 0 {
+				t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+			}
+
+			versions, err := store.ListSecretVersions(context.Background(), "config")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(versions) != 1 || versions[0].Version != base.Version {
+				t.Fatalf("got %d versions, want the edited version updated in place", len(versions))
+			}
+			if versions[0].Enabled != tt.wantEnabled {
+				t.Errorf("Enabled = %t, want %t", versions[0].Enabled, tt.wantEnabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// waitFlags are the flags that make GUI editors block until the file is
+// closed. Without them the editor returns at once and the edit is lost.
+var waitFlags = map[string][]string{
+	"code":          {"--wait", "-w"},
+	"code-insiders": {"--wait", "-w"},
+	"codium":        {"--wait", "-w"},
+	"cursor":        {"--wait", "-w"},
+	"subl":          {"--wait", "-w"},
+	"zed":           {"--wait", "-w"},
+	"mate":          {"--wait", "-w"},
+	"gvim":          {"--nofork", "-f"},
+	"mvim":          {"--nofork", "-f"},
+}
+
+// editorArgs splits an editor command line into arguments and adds the wait
+// flag for known GUI editors when it is missing
+func editorArgs(editorCmd string) ([]string, error) {
+	args, err := splitWords(editorCmd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor command %q: %w", editorCmd, err)
+	}
+	if len(args) == 0 {
+		return nil, errors.New("editor command is empty")
+	}
+
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	if flags, ok := waitFlags[name]; ok && !slices.ContainsFunc(args[1:], func(arg string) bool {
+		return slices.Contains(flags, arg)
+	}) {
+		args = append(args, flags[0])
+	}
+	return args, nil
+}
+
+// splitWords splits a command line the way a POSIX shell would for simple
+// words: whitespace separates arguments, single quotes are literal, and
+// backslashes escape the next character outside quotes (and only \, ", $
+// and ` inside double quotes)
+func splitWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune(`\"$`+"`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
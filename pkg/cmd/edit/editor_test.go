@@ -0,0 +1,92 @@
+package edit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "   ", want: nil},
+		{in: "vim", want: []string{"vim"}},
+		{in: "  code   --wait\t-n ", want: []string{"code", "--wait", "-n"}},
+		{in: `'/Applications/My Editor.app/bin/edit' -w`, want: []string{"/Applications/My Editor.app/bin/edit", "-w"}},
+		{in: `"/opt/my editor/bin/ed" --flag="a b"`, want: []string{"/opt/my editor/bin/ed", "--flag=a b"}},
+		{in: `/opt/my\ editor/ed`, want: []string{"/opt/my editor/ed"}},
+		{in: `'it''s'`, want: []string{"its"}},
+		{in: `'a\b'`, want: []string{`a\b`}},
+		{in: `"a\b \" \\ \$"`, want: []string{`a\b " \ $`}},
+		{in: `""`, want: []string{""}},
+		{in: `vim ''`, want: []string{"vim", ""}},
+		{in: `a"b c"d`, want: []string{"ab cd"}},
+		{in: `'unterminated`, wantErr: true},
+		{in: `"unterminated`, wantErr: true},
+		{in: `trailing\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := splitWords(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("splitWords(%q) = %q, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitWords(%q): %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitWords(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorArgs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "vim", want: []string{"vim"}},
+		{in: "code", want: []string{"code", "--wait"}},
+		{in: "code -w", want: []string{"code", "-w"}},
+		{in: "code --new-window --wait", want: []string{"code", "--new-window", "--wait"}},
+		{in: `"/usr/local/bin/subl" -n`, want: []string{"/usr/local/bin/subl", "-n", "--wait"}},
+		{in: `C:/Tools/code.exe`, want: []string{"C:/Tools/code.exe", "--wait"}},
+		{in: "gvim", want: []string{"gvim", "--nofork"}},
+		{in: "gvim -f", want: []string{"gvim", "-f"}},
+		{in: "  ", wantErr: true},
+		{in: `"vim`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := editorArgs(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("editorArgs(%q) = %q, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("editorArgs(%q): %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("editorArgs(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTempFileExt(t *testing.T) {
+	for format, want := range map[string]string{"json": ".json", "yaml": ".yaml", "pem": ".pem", "env": ".env", "none": ".tmp", "": ".tmp"} {
+		if got := tempFileExt(format); got != want {
+			t.Errorf("tempFileExt(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
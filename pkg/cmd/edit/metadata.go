@@ -0,0 +1,99 @@
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelimiter = "---\n"
+
+// metadata is the front matter header of a --with-metadata edit. A missing
+// enabled key leaves the version's state alone rather than disabling it.
+type metadata struct {
+	ContentType string            `yaml:"contentType"`
+	Enabled     *bool             `yaml:"enabled"`
+	NotBefore   *time.Time        `yaml:"notBefore"`
+	Expires     *time.Time        `yaml:"expires"`
+	Tags        map[string]string `yaml:"tags"`
+}
+
+func metadataOf(v *keyvault.SecretVersion) metadata {
+	enabled := v.Enabled
+	return metadata{
+		ContentType: v.ContentType,
+		Enabled:     &enabled,
+		NotBefore:   v.NotBefore,
+		Expires:     v.ExpiresOn,
+		Tags:        v.Tags,
+	}
+}
+
+// clearsDates reports whether m removes a date that old has set. Property
+// updates leave missing dates untouched, so clearing one needs a new version.
+func (m metadata) clearsDates(old metadata) bool {
+	return (m.NotBefore == nil && old.NotBefore != nil) ||
+		(m.Expires == nil && old.Expires != nil)
+}
+
+// attributes converts the header into attributes for the store. Tags are
+// never nil so that removing every tag clears them. Without an enabled key
+// an updated version keeps its state and a new version is enabled.
+func (m metadata) attributes() keyvault.SecretAttributes {
+	attrs := keyvault.SecretAttributes{
+		ContentType: &m.ContentType,
+		Enabled:     m.Enabled,
+		NotBefore:   m.NotBefore,
+		ExpiresOn:   m.Expires,
+		Tags:        m.Tags,
+	}
+	if attrs.Tags == nil {
+		attrs.Tags = map[string]string{}
+	}
+	return attrs
+}
+
+// renderDocument renders a secret as a YAML front matter header followed by
+// its value
+func renderDocument(v *keyvault.SecretVersion) (string, error) {
+	header, err := yaml.Marshal(metadataOf(v))
+	if err != nil {
+		return "", fmt.Errorf("failed to render metadata: %w", err)
+	}
+	return frontMatterDelimiter + string(header) + frontMatterDelimiter + v.Value, nil
+}
+
+// parseDocument splits a document written by renderDocument back into its
+// header and value. Everything after the closing delimiter is the value.
+func parseDocument(doc string) (metadata, string, error) {
+	var meta metadata
+
+	if !strings.HasPrefix(doc, frontMatterDelimiter) {
+		return meta, "", errors.New("metadata header must start with a '---' line")
+	}
+	rest := doc[len(frontMatterDelimiter):]
+
+	end := strings.Index(rest, "\n"+frontMatterDelimiter)
+	header := ""
+	switch {
+	case strings.HasPrefix(rest, frontMatterDelimiter):
+		rest = rest[len(frontMatterDelimiter):]
+	case end >= 0:
+		header = rest[:end+1]
+		rest = rest[end+1+len(frontMatterDelimiter):]
+	default:
+		return meta, "", errors.New("metadata header is missing its closing '---' line")
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(header))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&meta); err != nil && !errors.Is(err, io.EOF) {
+		return meta, "", fmt.Errorf("invalid metadata header: %w", err)
+	}
+	return meta, rest, nil
+}
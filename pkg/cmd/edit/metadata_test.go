@@ -0,0 +1,144 @@
+package edit
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestParseDocument(t *testing.T) {
+	expires := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		doc       string
+		wantMeta  metadata
+		wantValue string
+		wantErr   string
+	}{
+		{
+			name:      "header and value",
+			doc:       "---\ncontentType: text/plain\nenabled: true\ntags:\n  env: prod\n---\nsecret\n",
+			wantMeta:  metadata{ContentType: "text/plain", Enabled: enabled(true), Tags: map[string]string{"env": "prod"}},
+			wantValue: "secret\n",
+		},
+		{
+			name:      "dates",
+			doc:       "---\nenabled: false\nexpires: 2025-06-01T00:00:00Z\n---\nv",
+			wantMeta:  metadata{Enabled: enabled(false), Expires: &expires},
+			wantValue: "v",
+		},
+		{
+			name:      "enabled left out",
+			doc:       "---\ncontentType: text/plain\n---\nv",
+			wantMeta:  metadata{ContentType: "text/plain"},
+			wantValue: "v",
+		},
+		{
+			name:      "empty header",
+			doc:       "---\n---\nvalue",
+			wantValue: "value",
+		},
+		{
+			name:      "empty value",
+			doc:       "---\nenabled: true\n---\n",
+			wantMeta:  metadata{Enabled: enabled(true)},
+			wantValue: "",
+		},
+		{
+			name:      "value containing a delimiter",
+			doc:       "---\nenabled: true\n---\na\n---\nb",
+			wantMeta:  metadata{Enabled: enabled(true)},
+			wantValue: "a\n---\nb",
+		},
+		{
+			name:    "no opening delimiter",
+			doc:     "enabled: true\n---\nvalue",
+			wantErr: "must start with",
+		},
+		{
+			name:    "no closing delimiter",
+			doc:     "---\nenabled: true\nvalue",
+			wantErr: "missing its closing",
+		},
+		{
+			name:    "unknown field",
+			doc:     "---\nenabled: true\ncolour: red\n---\nvalue",
+			wantErr: "invalid metadata header",
+		},
+		{
+			name:    "invalid yaml",
+			doc:     "---\ntags: [a\n---\nvalue",
+			wantErr: "invalid metadata header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, value, err := parseDocument(tt.doc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseDocument error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDocument: %v", err)
+			}
+			if !meta.equal(tt.wantMeta) {
+				t.Errorf("metadata = %+v, want %+v", meta, tt.wantMeta)
+			}
+			if value != tt.wantValue {
+				t.Errorf("value = %q, want %q", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestRenderDocumentRoundTrip(t *testing.T) {
+	notBefore := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	v := &keyvault.SecretVersion{
+		Value:       "line one\n---\nline three",
+		ContentType: "application/x-pem-file",
+		Enabled:     true,
+		NotBefore:   &notBefore,
+		Tags:        map[string]string{"env": "prod", "owner": "payments team"},
+	}
+
+	doc, err := renderDocument(v)
+	if err != nil {
+		t.Fatalf("renderDocument: %v", err)
+	}
+	meta, value, err := parseDocument(doc)
+	if err != nil {
+		t.Fatalf("parseDocument: %v\n%s", err, doc)
+	}
+	if !meta.equal(metadataOf(v)) {
+		t.Errorf("metadata = %+v, want %+v", meta, metadataOf(v))
+	}
+	if value != v.Value {
+		t.Errorf("value = %q, want %q", value, v.Value)
+	}
+}
+
+func enabled(b bool) *bool {
+	return &b
+}
+
+// equal reports whether two headers describe the same attributes
+func (m metadata) equal(other metadata) bool {
+	return m.ContentType == other.ContentType &&
+		equalPtr(m.Enabled, other.Enabled, func(a, b bool) bool { return a == b }) &&
+		equalPtr(m.NotBefore, other.NotBefore, time.Time.Equal) &&
+		equalPtr(m.Expires, other.Expires, time.Time.Equal) &&
+		maps.Equal(m.Tags, other.Tags)
+}
+
+func equalPtr[T any](a, b *T, eq func(T, T) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return eq(*a, *b)
+}
@@ -0,0 +1,76 @@
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// editBuffer holds the plaintext value while the editor has it open
+type editBuffer struct {
+	// path is the file handed to the editor
+	path string
+	// location describes where the buffer lives, for the user
+	location string
+	inMemory bool
+	release  func() error
+}
+
+// tempDirCandidates lists the directories to try for the edit buffer, most
+// preferred first. Only memory-backed ones are used without --allow-disk-temp.
+func tempDirCandidates() []string {
+	var dirs []string
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return append(dirs, "/dev/shm", os.TempDir())
+}
+
+// newEditBuffer writes content to a buffer the editor can open. Overwriting
+// a file before deleting it does not reliably erase it from disk, so it
+// prefers a memory-backed filesystem. Where those can be detected it only
+// falls back to the system temp dir when allowDisk is set; elsewhere the
+// temp dir is used and the caller warns about it.
+//
+// The buffer is a plain file rather than an anonymous memory file: editors
+// put swap and backup files next to the file they edit, so those stay in
+// memory too, while a /proc path sends them to the home or root directory.
+func newEditBuffer(content, ext string, allowDisk bool) (*editBuffer, error) {
+	candidates := tempDirCandidates()
+	for _, dir := range candidates {
+		if isMemoryBacked(dir) && isWritableDir(dir) {
+			return fileBuffer(dir, content, ext, true)
+		}
+	}
+
+	if !allowDisk && canDetectMemoryBacked {
+		return nil, fmt.Errorf("no memory-backed temp directory found (tried %s); pass --allow-disk-temp to use %s",
+			strings.Join(candidates, ", "), os.TempDir())
+	}
+	return fileBuffer(os.TempDir(), content, ext, false)
+}
+
+// fileBuffer creates the edit buffer as a file in a private directory
+// under dir. The directory keeps the editor's swap and backup files out of
+// reach of other users, and is removed with them on release.
+func fileBuffer(dir, content, ext string, inMemory bool) (*editBuffer, error) {
+	private, err := os.MkdirTemp(dir, "kv-edit-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	path, err := createSecureTempFile(private, content, ext)
+	if err != nil {
+		_ = os.RemoveAll(private)
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	return &editBuffer{
+		path:     path,
+		location: private,
+		inMemory: inMemory,
+		release: func() error {
+			return errors.Join(secureDelete(path), os.RemoveAll(private))
+		},
+	}, nil
+}
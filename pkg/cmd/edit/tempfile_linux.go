@@ -0,0 +1,20 @@
+package edit
+
+import "golang.org/x/sys/unix"
+
+// canDetectMemoryBacked is set where isMemoryBacked can tell tmpfs apart
+// from disk, so a disk-backed temp dir can be refused
+const canDetectMemoryBacked = true
+
+// isMemoryBacked reports whether dir is on tmpfs or ramfs
+func isMemoryBacked(dir string) bool {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(dir, &fs); err != nil {
+		return false
+	}
+	return fs.Type == unix.TMPFS_MAGIC || fs.Type == unix.RAMFS_MAGIC
+}
+
+func isWritableDir(dir string) bool {
+	return unix.Access(dir, unix.W_OK|unix.X_OK) == nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package edit
+
+// canDetectMemoryBacked is false outside Linux: the edit buffer goes to the
+// system temp dir with a warning instead of being refused
+const canDetectMemoryBacked = false
+
+// isMemoryBacked is only implemented on Linux; elsewhere every directory is
+// treated as disk-backed
+func isMemoryBacked(dir string) bool {
+	return false
+}
+
+func isWritableDir(dir string) bool {
+	return false
+}
@@ -0,0 +1,61 @@
+package edit
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestEditBufferRoundTrip(t *testing.T) {
+	buffers := map[string]func() (*editBuffer, error){
+		"preferred": func() (*editBuffer, error) { return newEditBuffer("old", ".json", false) },
+		"file":      func() (*editBuffer, error) { return fileBuffer(t.TempDir(), "old", ".json", false) },
+	}
+	for name, create := range buffers {
+		t.Run(name, func(t *testing.T) {
+			if name == "preferred" && runtime.GOOS != "linux" {
+				t.Skip("memory-backed buffers are only detected on Linux")
+			}
+
+			buf, err := create()
+			if err != nil {
+				t.Fatalf("creating buffer: %v", err)
+			}
+			if name == "preferred" && !buf.inMemory {
+				t.Errorf("buffer in %s is not memory-backed", buf.location)
+			}
+
+			dir := filepath.Dir(buf.path)
+			if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+				t.Errorf("buffer directory %s: %v, %v; want a private directory", dir, info, err)
+			}
+
+			got, err := os.ReadFile(buf.path)
+			if err != nil || string(got) != "old" {
+				t.Fatalf("ReadFile = %q, %v", got, err)
+			}
+
+			// An editor saving in place, with a shorter value
+			if err := os.WriteFile(buf.path, []byte("new"), 0600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if got, err := os.ReadFile(buf.path); err != nil || string(got) != "new" {
+				t.Errorf("after save ReadFile = %q, %v", got, err)
+			}
+
+			// Editors keep swap and backup files next to the buffer
+			swap := filepath.Join(dir, "."+filepath.Base(buf.path)+".swp")
+			if err := os.WriteFile(swap, []byte("new"), 0600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			if err := buf.release(); err != nil {
+				t.Fatalf("release: %v", err)
+			}
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Errorf("buffer directory still exists after release: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+var version string
+
+var GetCmd = &cobra.Command{
+	Use:   "get [vault-name] <secret-name>",
+	Short: "Print a secret value from Azure Key Vault",
+	Long: `Print the value of a secret to stdout for use in scripts.
+
+The value is written as-is, without a trailing newline. Use --output json,
+yaml, table or env to include the version's metadata. The value is always
+included, so --reveal is not needed.
+
+Exit codes:
+  1  any other error
+  2  secret or version not found
+  3  access denied
+  4  version is disabled`,
+	Annotations: map[string]string{root.ValuesAnnotation: root.ValuesAlways},
+	Args:        cobra.RangeArgs(1, 2),
+	Run:         runGet,
+}
+
+func init() {
+	GetCmd.Flags().StringVar(&version, "version", "", "Version to read (default: latest)")
+	root.RootCmd.AddCommand(GetCmd)
+}
+
+func runGet(cmd *cobra.Command, args []string) {
+	vaultName, args, err := root.ResolveTarget(cmd, args, 2)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	secret, err := client.GetSecret(ctx, secretName, version)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	// Scripts rely on the raw value, so a profile's default output format
+	// only applies when --output is passed explicitly
+	if !cmd.Flags().Changed("output") {
+		fmt.Print(secret.Value)
+		return
+	}
+
+	if err := output.WriteOne(os.Stdout, root.Output, output.FromVersion(secretName, *secret, true)); err != nil {
+		root.ExitWithError(fmt.Errorf("failed to write output: %w", err))
+	}
+}
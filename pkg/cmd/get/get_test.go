@@ -0,0 +1,82 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+// forbiddenStore denies access to every secret
+type forbiddenStore struct {
+	*keyvault.MemoryStore
+}
+
+func (forbiddenStore) GetSecret(ctx context.Context, name, version string) (*keyvault.SecretVersion, error) {
+	return nil, fmt.Errorf("%w: no get permission", keyvault.ErrForbidden)
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	store := keyvault.NewMemoryStore()
+	old, err := store.SetSecret(ctx, "db", "line one\nline two\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	off := false
+	disabled, err := store.SetSecret(ctx, "db", "new", &keyvault.SecretAttributes{Enabled: &off})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SetSecret(ctx, "plain", "hunter2", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		store      keyvault.SecretStore
+		args       []string
+		wantStdout string
+		wantExit   int
+	}{
+		{"raw value without trailing newline", store, []string{"get", "vault", "plain"}, "hunter2", 0},
+		{"value kept as-is", store, []string{"get", "vault", "db", "--version", old.Version}, "line one\nline two\n", 0},
+		{"version prefix is not guessed", store, []string{"get", "vault", "db", "--version", old.Version[:6]}, "", 2},
+		{"missing secret", store, []string{"get", "vault", "missing"}, "", 2},
+		{"disabled latest", store, []string{"get", "vault", "db"}, "", 4},
+		{"disabled version", store, []string{"get", "vault", "db", "--version", disabled.Version}, "", 4},
+		{"access denied", forbiddenStore{store}, []string{"get", "vault", "plain"}, "", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := roottest.Run(t, tt.store, "", tt.args...)
+			if res.ExitCode != tt.wantExit {
+				t.Errorf("exit code = %d, want %d (stderr: %s)", res.ExitCode, tt.wantExit, res.Stderr)
+			}
+			if res.Stdout != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", res.Stdout, tt.wantStdout)
+			}
+			if tt.wantExit != 0 && res.Stderr == "" {
+				t.Error("no error message on stderr")
+			}
+		})
+	}
+}
+
+func TestGetWithOutput(t *testing.T) {
+	store := keyvault.NewMemoryStore()
+	if _, err := store.SetSecret(context.Background(), "db", "hunter2", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	res := roottest.Run(t, store, "", "get", "vault", "db", "-o", "json")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, `"value": "hunter2"`) {
+		t.Errorf("stdout = %s, want the value included without --reveal", res.Stdout)
+	}
+}
@@ -0,0 +1,147 @@
+package list
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/internal/listtui"
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/cmd/edit"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/cmd/show"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var ListCmd = &cobra.Command{
+	Use:   "list [vault-name]",
+	Short: "Browse secrets in Azure Key Vault",
+	Long: `Browse all secrets in Azure Key Vault using an interactive TUI.
+
+Type / to fuzzy filter by name or tag, Enter to browse the versions of the
+selected secret and e to edit it.
+
+With --output the secrets are printed instead. --reveal adds the value of
+each secret's latest version, e.g. kv list my-vault -o env --reveal.
+Without a terminal a plain table is printed.`,
+	Annotations: map[string]string{root.ValuesAnnotation: root.ValuesWithReveal},
+	Args:        cobra.MaximumNArgs(1),
+	Run:         runList,
+}
+
+func init() {
+	root.RootCmd.AddCommand(ListCmd)
+}
+
+func runList(cmd *cobra.Command, args []string) {
+	vaultName, _, err := root.ResolveTarget(cmd, args, 1)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	// Fall back to a table when there is no terminal for the TUI
+	format := root.Output
+	if format == "" && !root.Interactive() {
+		format = output.Table
+	}
+
+	if format != "" {
+		if err := printSecrets(ctx, client, format); err != nil {
+			root.ExitWithError(err)
+		}
+		return
+	}
+
+	if err := browseSecrets(ctx, client, vaultName); err != nil {
+		root.ExitWithError(err)
+	}
+}
+
+// printSecrets writes the secrets in the vault in the given format. With
+// --reveal the latest version of each secret is fetched for its value.
+func printSecrets(ctx context.Context, store keyvault.SecretStore, format string) error {
+	secrets, err := store.ListSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	out := make([]output.Secret, len(secrets))
+	for i, secret := range secrets {
+		out[i] = output.FromProperties(secret)
+	}
+	if !root.Reveal {
+		return output.Write(os.Stdout, format, out)
+	}
+
+	names := make([]string, len(secrets))
+	for i, secret := range secrets {
+		names[i] = secret.Name
+	}
+	latest, err := keyvault.FetchLatest(ctx, store, names, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch secret values: %w", err)
+	}
+	for i, v := range latest {
+		if v.ValueErr != nil {
+			out[i].Error = v.ValueErr.Error()
+			continue
+		}
+		out[i] = output.FromVersion(names[i], v, true)
+	}
+
+	return output.Write(os.Stdout, format, out)
+}
+
+// browseSecrets shows the secret list until the user quits, running the
+// chosen action for a secret and returning to the list afterwards
+func browseSecrets(ctx context.Context, store keyvault.SecretStore, vaultName string) error {
+	secrets, err := store.ListSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	if len(secrets) == 0 {
+		fmt.Println("No secrets found in this vault.")
+		return nil
+	}
+
+	model := listtui.NewModel(secrets, vaultName)
+	for {
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		finalModel, err := p.Run()
+		if err != nil {
+			return fmt.Errorf("TUI error: %w", err)
+		}
+
+		result := finalModel.(listtui.Model)
+		secretName := result.Selected()
+
+		var status string
+		switch result.Action() {
+		case listtui.ActionBrowse:
+			if err := show.Browse(ctx, store, secretName); err != nil {
+				status = fmt.Sprintf("Error: %v", err)
+			}
+		case listtui.ActionEdit:
+			if _, err := edit.EditSecret(ctx, store, secretName); err != nil {
+				status = fmt.Sprintf("Error: %v", err)
+			}
+		default:
+			return nil
+		}
+
+		// Refresh so edits show up with their new timestamps
+		if refreshed, err := store.ListSecrets(ctx); err == nil {
+			secrets = refreshed
+		}
+		model = result.Resume(secrets, status)
+	}
+}
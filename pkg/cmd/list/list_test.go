@@ -0,0 +1,50 @@
+package list
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestListRevealEnv(t *testing.T) {
+	ctx := context.Background()
+	store := keyvault.NewMemoryStore()
+	for _, name := range []string{"db-password", "api-key", "off"} {
+		if _, err := store.SetSecret(ctx, name, "value of "+name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	disabled := false
+	if _, err := store.UpdateSecretProperties(ctx, "off", "", keyvault.SecretAttributes{Enabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	res := roottest.Run(t, store, "", "list", "my-vault", "--output", "env", "--reveal")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	want := "API_KEY='value of api-key'\n" +
+		"DB_PASSWORD='value of db-password'\n" +
+		"# OFF: " + keyvault.ErrSecretDisabled.Error()
+	if !strings.HasPrefix(res.Stdout, want) {
+		t.Errorf("stdout = %q, want it to start with %q", res.Stdout, want)
+	}
+}
+
+func TestListWithoutReveal(t *testing.T) {
+	store := keyvault.NewMemoryStore()
+	if _, err := store.SetSecret(context.Background(), "db", "hidden", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	res := roottest.Run(t, store, "", "list", "my-vault", "--output", "json")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, `"name": "db"`) || strings.Contains(res.Stdout, "hidden") {
+		t.Errorf("stdout = %s", res.Stdout)
+	}
+}
@@ -0,0 +1,88 @@
+package purge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/spf13/cobra"
+)
+
+var confirm string
+
+var PurgeCmd = &cobra.Command{
+	Use:   "purge [vault-name] <secret-name>",
+	Short: "Permanently delete a soft-deleted secret",
+	Long: `Permanently delete a soft-deleted secret and all its versions. This cannot
+be undone.
+
+The secret name has to be typed to confirm, or passed with --confirm when
+there is no terminal. Vaults with purge protection refuse to purge; their
+deleted secrets are purged automatically on the scheduled purge date.`,
+	Example: `  kv purge my-vault old-api-key
+  kv purge my-vault old-api-key --confirm old-api-key`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runPurge,
+}
+
+func init() {
+	PurgeCmd.Flags().StringVar(&confirm, "confirm", "", "Secret name, to confirm without a prompt")
+	root.RootCmd.AddCommand(PurgeCmd)
+}
+
+func runPurge(cmd *cobra.Command, args []string) {
+	vaultName, args, err := root.ResolveTarget(cmd, args, 2)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	deleted, err := findDeleted(ctx, client, secretName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	if !keyvault.Purgeable(deleted.RecoveryLevel) {
+		purgeDate := "its scheduled purge date"
+		if deleted.ScheduledPurgeDate != nil {
+			purgeDate = deleted.ScheduledPurgeDate.Local().Format("2006-01-02 15:04")
+		}
+		root.ExitWithError(fmt.Errorf("purge protection is enabled on this vault: '%s' will be purged automatically on %s", secretName, purgeDate))
+	}
+
+	question := fmt.Sprintf("Purging '%s' permanently deletes it and all its versions. This cannot be undone.", secretName)
+	ok, err := root.ConfirmTyped(question, secretName, confirm)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	if !ok {
+		fmt.Fprintln(root.Progress(), "Aborted. Secret not purged.")
+		return
+	}
+
+	fmt.Fprintf(root.Progress(), "Purging '%s'...\n", secretName)
+	if err := client.PurgeDeletedSecret(ctx, secretName); err != nil {
+		root.ExitWithError(err)
+	}
+	fmt.Fprintf(root.Progress(), "✓ Secret '%s' purged\n", secretName)
+}
+
+// findDeleted looks up a secret among the vault's deleted secrets
+func findDeleted(ctx context.Context, store keyvault.SecretStore, secretName string) (*keyvault.DeletedSecret, error) {
+	secrets, err := store.ListDeletedSecrets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted secrets: %w", err)
+	}
+	for i := range secrets {
+		if secrets[i].Name == secretName {
+			return &secrets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no deleted secret named %s", keyvault.ErrSecretNotFound, secretName)
+}
@@ -0,0 +1,95 @@
+package purge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		name          string
+		recoveryLevel string
+		secret        string
+		args          []string
+		wantExit      int
+		wantStderr    string
+		wantPurged    bool
+	}{
+		{
+			name:          "without a terminal",
+			recoveryLevel: "Recoverable+Purgeable",
+			secret:        "db",
+			wantExit:      1,
+			wantStderr:    "no terminal attached to confirm: re-run with --confirm db",
+		},
+		{
+			name:          "wrong name",
+			recoveryLevel: "Recoverable+Purgeable",
+			secret:        "db",
+			args:          []string{"--confirm", "DB"},
+			wantExit:      1,
+			wantStderr:    `--confirm "DB" does not match 'db'`,
+		},
+		{
+			name:          "purge protection",
+			recoveryLevel: "Recoverable",
+			secret:        "db",
+			args:          []string{"--confirm", "db"},
+			wantExit:      1,
+			wantStderr:    "purge protection is enabled on this vault",
+		},
+		{
+			name:          "not deleted",
+			recoveryLevel: "Recoverable+Purgeable",
+			secret:        "live",
+			args:          []string{"--confirm", "live"},
+			wantExit:      2,
+			wantStderr:    "no deleted secret named live",
+		},
+		{
+			name:          "confirmed",
+			recoveryLevel: "Recoverable+Purgeable",
+			secret:        "db",
+			args:          []string{"--confirm", "db"},
+			wantPurged:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := keyvault.NewMemoryStore()
+			store.RecoveryLevel = tt.recoveryLevel
+			for _, name := range []string{"db", "live"} {
+				if _, err := store.SetSecret(ctx, name, "v", nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := store.DeleteSecret(ctx, "db"); err != nil {
+				t.Fatal(err)
+			}
+
+			res := roottest.Run(t, store, "", append([]string{"purge", "vault", tt.secret}, tt.args...)...)
+			if res.ExitCode != tt.wantExit {
+				t.Fatalf("exit code %d, want %d (stderr: %s)", res.ExitCode, tt.wantExit, res.Stderr)
+			}
+			if !strings.Contains(res.Stderr, tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", res.Stderr, tt.wantStderr)
+			}
+
+			listed, err := store.ListDeletedSecrets(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if purged := len(listed) == 0; purged != tt.wantPurged {
+				t.Errorf("deleted secrets = %+v, want purged: %t", listed, tt.wantPurged)
+			}
+			if _, err := store.GetSecret(ctx, "live", ""); err != nil {
+				t.Errorf("live secret affected: %v", err)
+			}
+		})
+	}
+}
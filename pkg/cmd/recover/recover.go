@@ -0,0 +1,55 @@
+package recover
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/spf13/cobra"
+)
+
+var RecoverCmd = &cobra.Command{
+	Use:   "recover [vault-name] <secret-name>",
+	Short: "Restore a soft-deleted secret",
+	Long: `Restore a soft-deleted secret with all its versions, tags and attributes.
+
+The command waits until the secret can be read again. Use kv deleted list
+to see the secrets that can be recovered.`,
+	Example: `  kv recover my-vault old-api-key`,
+	Args:    cobra.RangeArgs(1, 2),
+	Run:     runRecover,
+}
+
+func init() {
+	root.RootCmd.AddCommand(RecoverCmd)
+}
+
+func runRecover(cmd *cobra.Command, args []string) {
+	vaultName, args, err := root.ResolveTarget(cmd, args, 2)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	fmt.Fprintf(root.Progress(), "Recovering '%s'...\n", secretName)
+	latest, err := client.RecoverDeletedSecret(ctx, secretName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	fmt.Fprintf(root.Progress(), "✓ Secret '%s' recovered\n", secretName)
+
+	if root.Output != "" {
+		if err := output.WriteOne(os.Stdout, root.Output, output.FromVersion(secretName, *latest, false)); err != nil {
+			root.ExitWithError(fmt.Errorf("failed to write output: %w", err))
+		}
+	}
+}
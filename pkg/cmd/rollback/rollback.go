@@ -0,0 +1,159 @@
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/internal/difftui"
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/internal/textdiff"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	withMetadata   bool
+	skipValidation bool
+	yes            bool
+)
+
+var RollbackCmd = &cobra.Command{
+	Use:   "rollback [vault-name] <secret-name> <version>",
+	Short: "Restore a previous version of a secret",
+	Long: `Re-publish the value of a previous version as the new latest version.
+
+The version can be given as its full ID or a unique prefix. Previous versions
+are kept, so a rollback can itself be rolled back.
+
+Without a terminal the changes are printed as a unified diff instead of the
+review screen, and --yes is required to write them, as with kv edit.`,
+	Annotations: map[string]string{root.ValuesAnnotation: root.ValuesWithReveal},
+	Args:        cobra.RangeArgs(2, 3),
+	Run:         runRollback,
+}
+
+func init() {
+	RollbackCmd.Flags().BoolVar(&withMetadata, "with-metadata", false, "Also restore the version's tags and content type")
+	RollbackCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Skip the diff confirmation step")
+	RollbackCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Roll back without review when stdin is not a terminal")
+	root.RootCmd.AddCommand(RollbackCmd)
+}
+
+func runRollback(cmd *cobra.Command, args []string) {
+	vaultName, args, err := root.ResolveTarget(cmd, args, 3)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
+	versionID := args[1]
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	versions, err := client.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		root.ExitWithError(fmt.Errorf("failed to list secret versions: %w", err))
+	}
+
+	target, err := keyvault.FindVersion(versions, versionID)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	secret, err := client.GetSecret(ctx, secretName, target.Version)
+	if err != nil {
+		root.ExitWithError(fmt.Errorf("failed to get version %s: %w", keyvault.ShortVersion(target.Version), err))
+	}
+
+	created, err := Rollback(ctx, client, secretName, *secret, withMetadata, skipValidation)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	if created != nil && root.Output != "" {
+		if err := output.WriteOne(os.Stdout, root.Output, output.FromVersion(secretName, *created, root.Reveal)); err != nil {
+			root.ExitWithError(fmt.Errorf("failed to write output: %w", err))
+		}
+	}
+}
+
+// Rollback re-publishes target, which must include its value, as the new
+// latest version after the user confirms the diff against the current
+// latest version. It returns the created version, or nil if nothing was
+// written.
+func Rollback(ctx context.Context, store keyvault.SecretStore, secretName string, target keyvault.SecretVersion, withMetadata, skipValidation bool) (*keyvault.SecretVersion, error) {
+	latest, err := keyvault.LatestVersion(ctx, store, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest version: %w", err)
+	}
+
+	if latest.Version == target.Version {
+		fmt.Fprintf(root.Progress(), "Version %s is already the latest version.\n", keyvault.ShortVersion(target.Version))
+		return nil, nil
+	}
+
+	// A disabled latest version can't be read, so the diff is against an
+	// empty value
+	if latest.ValueErr != nil {
+		fmt.Fprintf(root.Progress(), "The latest version %s is disabled; its value can't be shown in the diff.\n", keyvault.ShortVersion(latest.Version))
+	} else if latest.Value == target.Value && !withMetadata {
+		fmt.Fprintln(root.Progress(), "The latest version already has this value. Secret not updated.")
+		return nil, nil
+	}
+
+	// Without a terminal the diff can only be printed, and the rollback has
+	// to be confirmed up front with --yes
+	if !skipValidation && !root.Interactive() {
+		fmt.Fprint(root.Progress(), textdiff.Unified(
+			fmt.Sprintf("%s@%s", secretName, keyvault.ShortVersion(latest.Version)),
+			fmt.Sprintf("%s@%s", secretName, keyvault.ShortVersion(target.Version)),
+			latest.Value, target.Value, 3))
+		if !yes {
+			return nil, fmt.Errorf("no terminal attached: review the diff above and re-run with --yes to roll back '%s'", secretName)
+		}
+	}
+
+	// Show diff in TUI for confirmation unless skipped
+	if !skipValidation && root.Interactive() {
+		fmt.Fprintln(root.Progress(), "\nReview rollback...")
+		diffModel := difftui.NewModel(latest.Value, target.Value, secretName)
+		p := tea.NewProgram(diffModel, tea.WithAltScreen())
+
+		finalModel, err := p.Run()
+		if err != nil {
+			return nil, fmt.Errorf("diff viewer error: %w", err)
+		}
+
+		diffResult := finalModel.(difftui.Model)
+		if !diffResult.Confirmed() {
+			fmt.Fprintln(root.Progress(), "Rollback cancelled.")
+			return nil, nil
+		}
+	}
+
+	// Keep the current tags and content type unless asked to restore the old ones
+	source := latest
+	if withMetadata {
+		source = &target
+	}
+	attrs := &keyvault.SecretAttributes{
+		Tags: source.Tags,
+	}
+	if source.ContentType != "" {
+		attrs.ContentType = &source.ContentType
+	}
+
+	created, err := store.SetSecret(ctx, secretName, target.Value, attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back secret: %w", err)
+	}
+
+	fmt.Fprintf(root.Progress(), "✓ Secret '%s' rolled back to version %s (new version: %s)\n",
+		secretName, keyvault.ShortVersion(target.Version), keyvault.ShortVersion(created.Version))
+	return created, nil
+}
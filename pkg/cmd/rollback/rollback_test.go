@@ -0,0 +1,110 @@
+package rollback
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+// newStore returns a store holding two versions of "db", an hour apart
+func newStore(t *testing.T) (*keyvault.MemoryStore, []string) {
+	t.Helper()
+
+	store := keyvault.NewMemoryStore()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i, value := range []string{"old-value", "new-value"} {
+		createdOn := created.Add(time.Duration(i) * time.Hour)
+		v, err := store.AddVersion("db", keyvault.SecretVersion{
+			Value:     value,
+			Enabled:   true,
+			CreatedOn: &createdOn,
+			Tags:      map[string]string{"env": "dev"},
+		})
+		if err != nil {
+			t.Fatalf("AddVersion: %v", err)
+		}
+		ids = append(ids, v.Version)
+	}
+	return store, ids
+}
+
+func latest(t *testing.T, store keyvault.SecretStore) *keyvault.SecretVersion {
+	t.Helper()
+	v, err := store.GetSecret(context.Background(), "db", "")
+	if err != nil {
+		t.Fatalf("GetSecret: %v", err)
+	}
+	return v
+}
+
+func TestRollback(t *testing.T) {
+	store, ids := newStore(t)
+
+	res := roottest.Run(t, store, "", "rollback", "my-vault", "db", ids[0][:6], "--skip-validation")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+
+	got := latest(t, store)
+	if got.Version == ids[1] || got.Value != "old-value" || got.Tags["env"] != "dev" {
+		t.Errorf("latest = %s %q %v, want a new version with the old value and current tags", got.Version, got.Value, got.Tags)
+	}
+}
+
+func TestRollbackWithoutTerminalPrintsDiff(t *testing.T) {
+	store, ids := newStore(t)
+
+	res := roottest.Run(t, store, "", "rollback", "my-vault", "db", ids[0])
+	if res.ExitCode == 0 {
+		t.Fatal("rollback without --yes succeeded without a terminal")
+	}
+	if !strings.Contains(res.Stderr, "re-run with --yes") {
+		t.Errorf("stderr = %q, want it to point at --yes", res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "-new-value") || !strings.Contains(res.Stdout, "+old-value") {
+		t.Errorf("diff missing from output:\n%s", res.Stdout)
+	}
+	if got := latest(t, store); got.Version != ids[1] {
+		t.Errorf("latest changed to %s", got.Version)
+	}
+
+	// --yes writes after printing the same diff, like kv edit
+	res = roottest.Run(t, store, "", "rollback", "my-vault", "db", ids[0], "--yes")
+	if res.ExitCode != 0 {
+		t.Fatalf("--yes: exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "+old-value") {
+		t.Errorf("--yes: diff missing from output:\n%s", res.Stdout)
+	}
+	if got := latest(t, store); got.Value != "old-value" {
+		t.Errorf("--yes: latest = %q, want the old value", got.Value)
+	}
+}
+
+func TestRollbackFromDisabledLatest(t *testing.T) {
+	store, ids := newStore(t)
+	off := false
+	if _, err := store.UpdateSecretProperties(context.Background(), "db", ids[1], keyvault.SecretAttributes{Enabled: &off}); err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+
+	// The diff is against an empty value since the latest one can't be read
+	res := roottest.Run(t, store, "", "rollback", "my-vault", "db", ids[0])
+	if !strings.Contains(res.Stdout, "is disabled") || !strings.Contains(res.Stdout, "+old-value") {
+		t.Errorf("output:\n%s", res.Stdout)
+	}
+
+	res = roottest.Run(t, store, "", "rollback", "my-vault", "db", ids[0], "--skip-validation")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	got := latest(t, store)
+	if got.Value != "old-value" || got.Tags["env"] != "dev" {
+		t.Errorf("latest = %s %q %v, want the old value with the disabled version's tags", got.Version, got.Value, got.Tags)
+	}
+}
@@ -0,0 +1,107 @@
+package root
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/pkg/config"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ProfileName is the --profile flag, or the active profile once the
+	// config has been loaded
+	ProfileName string
+
+	// Profile holds the settings of the active profile
+	Profile config.Profile
+
+	// Aliases are the secret and vault aliases from the config file
+	Aliases map[string]config.Alias
+)
+
+// loadProfile reads the config file and fills in every flag the user did
+// not pass from the active profile
+func loadProfile(cmd *cobra.Command) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	ProfileName = cfg.ActiveProfile(ProfileName)
+	Profile = cfg.Profile(ProfileName)
+	Aliases = cfg.Aliases
+
+	flags := cmd.Flags()
+	defaults := []struct {
+		flag   string
+		target *string
+		value  string
+	}{
+		{"cloud", &CloudName, Profile.Cloud},
+		{"auth", &Auth.Auth, Profile.Auth},
+		{"tenant", &Auth.TenantID, Profile.Tenant},
+		{"client-id", &Auth.ClientID, Profile.ClientID},
+		{"output", &Output, Profile.Output},
+	}
+	for _, d := range defaults {
+		if d.value != "" && !flags.Changed(d.flag) {
+			*d.target = d.value
+		}
+	}
+
+	applyTheme(Profile.Theme)
+	return nil
+}
+
+// applyTheme sets up the TUI colors for a theme. An unknown theme only gets
+// a warning, so that every command, including the one to fix the setting,
+// keeps working.
+func applyTheme(theme string) {
+	switch theme {
+	case "", "default":
+	case "plain":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: unknown theme %q in profile %q, using the default theme (change it with: kv config set theme default)\n", theme, ProfileName)
+	}
+}
+
+// ResolveTarget separates the vault from the other arguments like
+// SplitVault, after expanding aliases. A secret alias in the first
+// argument stands for both the vault and the secret and wins over a vault
+// or secret of the same name; a vault alias stands for the vault.
+func ResolveTarget(cmd *cobra.Command, args []string, want int) (string, []string, error) {
+	if len(args) > 0 {
+		if alias, ok := Aliases[args[0]]; ok && alias.Secret != "" {
+			expanded := append([]string{alias.Vault, alias.Secret}, args[1:]...)
+			if err := cmd.ValidateArgs(expanded); err != nil {
+				return "", nil, fmt.Errorf("too many arguments: alias %q already names the vault and the secret (%s)", args[0], alias)
+			}
+			return alias.Vault, expanded[1:], nil
+		}
+	}
+
+	vault, rest, err := SplitVault(args, want)
+	if err != nil {
+		return "", nil, err
+	}
+	if alias, ok := Aliases[vault]; ok && alias.Secret == "" {
+		vault = alias.Vault
+	}
+	return vault, rest, nil
+}
+
+// SplitVault separates the vault from the other arguments of a command
+// whose first want arguments are required, the first being the vault. With
+// fewer arguments the vault comes from the active profile.
+func SplitVault(args []string, want int) (string, []string, error) {
+	if len(args) >= want {
+		return args[0], args[1:], nil
+	}
+	if Profile.Vault == "" {
+		return "", nil, fmt.Errorf("no vault given and profile %q has no default vault (set one with: kv config set vault <name>)", ProfileName)
+	}
+	return Profile.Vault, args, nil
+}
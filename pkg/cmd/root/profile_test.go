@@ -0,0 +1,105 @@
+package root
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestResolveTarget(t *testing.T) {
+	// A command taking [vault-name] <secret-name> <version>
+	cmd := &cobra.Command{Args: cobra.RangeArgs(2, 3)}
+
+	tests := []struct {
+		name         string
+		aliases      map[string]config.Alias
+		defaultVault string
+		args         []string
+		wantVault    string
+		wantRest     []string
+		wantErr      string
+	}{
+		{
+			name:      "vault given",
+			args:      []string{"my-vault", "db", "abc"},
+			wantVault: "my-vault", wantRest: []string{"db", "abc"},
+		},
+		{
+			name:         "vault from profile",
+			defaultVault: "default-vault",
+			args:         []string{"db", "abc"},
+			wantVault:    "default-vault", wantRest: []string{"db", "abc"},
+		},
+		{
+			name:    "no vault",
+			args:    []string{"db", "abc"},
+			wantErr: "no default vault",
+		},
+		{
+			name:      "secret alias",
+			aliases:   map[string]config.Alias{"db": {Vault: "prod-vault", Secret: "db-password"}},
+			args:      []string{"db", "abc"},
+			wantVault: "prod-vault", wantRest: []string{"db-password", "abc"},
+		},
+		{
+			name:         "secret alias wins over the default vault",
+			aliases:      map[string]config.Alias{"db": {Vault: "prod-vault", Secret: "db-password"}},
+			defaultVault: "default-vault",
+			args:         []string{"db", "abc"},
+			wantVault:    "prod-vault", wantRest: []string{"db-password", "abc"},
+		},
+		{
+			name:    "secret alias with a vault too",
+			aliases: map[string]config.Alias{"db": {Vault: "prod-vault", Secret: "db-password"}},
+			args:    []string{"db", "db-password", "abc"},
+			wantErr: `alias "db" already names the vault and the secret (prod-vault/db-password)`,
+		},
+		{
+			name:      "vault alias",
+			aliases:   map[string]config.Alias{"prod": {Vault: "prod-vault"}},
+			args:      []string{"prod", "db", "abc"},
+			wantVault: "prod-vault", wantRest: []string{"db", "abc"},
+		},
+		{
+			name:         "vault alias is not a secret",
+			aliases:      map[string]config.Alias{"prod": {Vault: "prod-vault"}},
+			defaultVault: "default-vault",
+			args:         []string{"prod", "abc"},
+			wantVault:    "default-vault", wantRest: []string{"prod", "abc"},
+		},
+		{
+			name:         "secret alias only in first position",
+			aliases:      map[string]config.Alias{"db": {Vault: "prod-vault", Secret: "db-password"}},
+			defaultVault: "default-vault",
+			args:         []string{"other", "db"},
+			wantVault:    "default-vault", wantRest: []string{"other", "db"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Aliases = tt.aliases
+			Profile = config.Profile{Vault: tt.defaultVault}
+			t.Cleanup(func() {
+				Aliases = nil
+				Profile = config.Profile{}
+			})
+
+			vault, rest, err := ResolveTarget(cmd, tt.args, 3)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveTarget error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTarget: %v", err)
+			}
+			if vault != tt.wantVault || !reflect.DeepEqual(rest, tt.wantRest) {
+				t.Errorf("ResolveTarget = %q %q, want %q %q", vault, rest, tt.wantVault, tt.wantRest)
+			}
+		})
+	}
+}
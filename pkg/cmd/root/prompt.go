@@ -0,0 +1,76 @@
+package root
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether f is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Interactive reports whether a TUI can be started: it needs a terminal on
+// both stdin and stdout
+func Interactive() bool {
+	return IsTerminal(os.Stdin) && IsTerminal(os.Stdout)
+}
+
+// Confirm asks a yes/no question on the terminal. An empty answer picks
+// defaultYes; a closed stdin counts as no.
+func Confirm(question string, defaultYes bool) bool {
+	hint := "[y/N]"
+	if defaultYes {
+		hint = "[Y/n]"
+	}
+	fmt.Fprintf(Progress(), "%s %s ", question, hint)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(Progress())
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// ConfirmName asks the user to type name to confirm a destructive action.
+// Only an exact match counts as yes.
+func ConfirmName(question, name string) bool {
+	fmt.Fprintf(Progress(), "%s\nType '%s' to confirm: ", question, name)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(Progress())
+		return false
+	}
+	return strings.TrimSpace(answer) == name
+}
+
+// ConfirmTyped guards an action that cannot be undone. A non-empty
+// confirmFlag (the --confirm value) must match name; otherwise name has to
+// be typed on the terminal. It reports false when the user declines.
+func ConfirmTyped(question, name, confirmFlag string) (bool, error) {
+	if confirmFlag != "" {
+		if confirmFlag != name {
+			return false, fmt.Errorf("--confirm %q does not match '%s'", confirmFlag, name)
+		}
+		return true, nil
+	}
+
+	if !IsTerminal(os.Stdin) {
+		return false, fmt.Errorf("no terminal attached to confirm: re-run with --confirm %s", name)
+	}
+	return ConfirmName(question, name), nil
+}
@@ -1,9 +1,14 @@
 package root
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/keyvault"
 	"github.com/spf13/cobra"
 )
 
@@ -11,6 +16,112 @@ var RootCmd = &cobra.Command{
 	Use:   "kv",
 	Short: "Azure Key Vault CLI tool",
 	Long:  `A CLI tool to browse and manage Azure Key Vault secrets with a beautiful TUI.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := loadProfile(cmd); err != nil {
+			ExitWithError(err)
+		}
+		if err := output.CheckFormat(Output); err != nil {
+			ExitWithError(err)
+		}
+		if err := checkEnvOutput(cmd); err != nil {
+			ExitWithError(err)
+		}
+	},
+}
+
+// ValuesAnnotation is the command annotation saying whether the command's
+// --output includes secret values, which env output consists of. It is
+// ValuesAlways or ValuesWithReveal; commands without it have no value to
+// show.
+const (
+	ValuesAnnotation = "kv/values"
+	ValuesAlways     = "always"
+	ValuesWithReveal = "reveal"
+)
+
+// checkEnvOutput refuses env output that could not be written, before the
+// command reads or changes anything. A profile's env default is dropped on
+// commands with no value to show.
+func checkEnvOutput(cmd *cobra.Command) error {
+	if Output != output.Env {
+		return nil
+	}
+
+	fromProfile := !cmd.Flags().Changed("output")
+	switch cmd.Annotations[ValuesAnnotation] {
+	case ValuesAlways:
+		return nil
+	case ValuesWithReveal:
+		if Reveal {
+			return nil
+		}
+		if fromProfile {
+			return fmt.Errorf("env output (the default of profile %q) requires --reveal", ProfileName)
+		}
+		return errors.New("env output requires --reveal")
+	}
+
+	if fromProfile {
+		Output = ""
+		return nil
+	}
+	return fmt.Errorf("env output is not supported by %s: it has no secret value to show", cmd.CommandPath())
+}
+
+var (
+	// Output is the --output format. Empty means the command's usual
+	// interactive or human-readable output.
+	Output string
+
+	// Reveal includes secret values in --output
+	Reveal bool
+
+	// CloudName is the --cloud flag. Empty means the cloud is inferred
+	// from the vault URL.
+	CloudName string
+
+	// Auth holds the --auth, --tenant, --client-id and
+	// --federated-token-file flags
+	Auth keyvault.ClientOptions
+)
+
+// NewStore opens the secret store for a vault URL. Commands go through
+// OpenStore instead of calling keyvault.NewClient directly so it can be
+// replaced, for example with a keyvault.MemoryStore in tests.
+var NewStore = func(vaultURL string, opts *keyvault.ClientOptions) (keyvault.SecretStore, error) {
+	client, err := keyvault.NewClient(vaultURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// OpenStore resolves a vault name or URL and opens its secret store. The
+// cloud comes from --cloud, then from the URL's suffix, then AzurePublic.
+func OpenStore(vault string) (keyvault.SecretStore, error) {
+	cloud := keyvault.AzurePublic
+	if CloudName != "" {
+		var err error
+		if cloud, err = keyvault.LookupCloud(CloudName); err != nil {
+			return nil, err
+		}
+	}
+
+	vaultURL, err := keyvault.VaultURL(vault, cloud)
+	if err != nil {
+		return nil, err
+	}
+	if inferred, ok := keyvault.CloudForURL(vaultURL); ok && CloudName == "" {
+		cloud = inferred
+	}
+
+	opts := Auth
+	opts.Cloud = cloud
+	store, err := NewStore(vaultURL, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Key Vault client: %w", err)
+	}
+	return store, nil
 }
 
 func Execute() error {
@@ -19,9 +130,52 @@ func Execute() error {
 
 func init() {
 	RootCmd.CompletionOptions.DisableDefaultCmd = true
+	RootCmd.PersistentFlags().StringVar(&ProfileName, "profile", "", "Config profile to use (default: the current profile)")
+	RootCmd.PersistentFlags().StringVarP(&Output, "output", "o", "", "Output format: json, yaml, table or env")
+	RootCmd.PersistentFlags().BoolVar(&Reveal, "reveal", false, "Include secret values in --output (redacted by default)")
+	RootCmd.PersistentFlags().StringVar(&Auth.Auth, "auth", "", "Credential to use: "+strings.Join(keyvault.AuthMethods, ", ")+" (default: the Azure default chain)")
+	RootCmd.PersistentFlags().StringVar(&Auth.TenantID, "tenant", "", "Tenant ID to authenticate in")
+	RootCmd.PersistentFlags().StringVar(&Auth.ClientID, "client-id", "", "Client ID of the service principal, workload or user-assigned managed identity")
+	RootCmd.PersistentFlags().StringVar(&Auth.TokenFile, "federated-token-file", "", "Token file for --auth workload (default: $AZURE_FEDERATED_TOKEN_FILE)")
+	RootCmd.PersistentFlags().StringVar(&CloudName, "cloud", "", "Azure cloud: "+strings.Join(keyvault.CloudNames(), ", ")+" (default: from the vault URL, else AzurePublic)")
+}
+
+// Progress is where commands print human-readable messages. With --output
+// they go to stderr so stdout only carries the structured output.
+func Progress() io.Writer {
+	if Output != "" {
+		return os.Stderr
+	}
+	return os.Stdout
 }
 
+// Exit codes let scripts tell common failures apart
+const (
+	ExitCodeError     = 1
+	ExitCodeNotFound  = 2
+	ExitCodeForbidden = 3
+	ExitCodeDisabled  = 4
+)
+
+// Exit ends the process with a code. Tests replace it to observe failures
+// without exiting.
+var Exit = os.Exit
+
 func ExitWithError(err error) {
 	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-	os.Exit(1)
+	Exit(exitCode(err))
+}
+
+// exitCode picks the exit code matching the kind of error
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, keyvault.ErrSecretNotFound):
+		return ExitCodeNotFound
+	case errors.Is(err, keyvault.ErrForbidden):
+		return ExitCodeForbidden
+	case errors.Is(err, keyvault.ErrSecretDisabled):
+		return ExitCodeDisabled
+	default:
+		return ExitCodeError
+	}
 }
@@ -0,0 +1,139 @@
+// Package roottest runs kv commands end to end against an in-memory store,
+// for use in the commands' tests
+package roottest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Result is what a command run produced
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+
+	// VaultURL is the vault the command opened, if any
+	VaultURL string
+}
+
+// exitPanic carries the exit code out of a command that called root.Exit
+type exitPanic struct {
+	code int
+}
+
+// Run executes kv with args against store, with stdin as its standard
+// input. Flags start from their defaults on every run. Unless the test set
+// KV_CONFIG, the config file is a fresh file in a temporary directory.
+func Run(t *testing.T, store keyvault.SecretStore, stdin string, args ...string) Result {
+	t.Helper()
+
+	if _, ok := os.LookupEnv("KV_CONFIG"); !ok {
+		t.Setenv("KV_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	}
+	resetFlags(root.RootCmd)
+
+	var result Result
+	restoreStore := root.NewStore
+	root.NewStore = func(vaultURL string, opts *keyvault.ClientOptions) (keyvault.SecretStore, error) {
+		result.VaultURL = vaultURL
+		return store, nil
+	}
+	restoreExit := root.Exit
+	root.Exit = func(code int) {
+		panic(exitPanic{code: code})
+	}
+	defer func() {
+		root.NewStore = restoreStore
+		root.Exit = restoreExit
+	}()
+
+	stdinFile := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(stdinFile, []byte(stdin), 0600); err != nil {
+		t.Fatalf("failed to write stdin: %v", err)
+	}
+	in, err := os.Open(stdinFile) // #nosec G304 - Temp file created above
+	if err != nil {
+		t.Fatalf("failed to open stdin: %v", err)
+	}
+	defer in.Close()
+
+	stdout, stderr := capture(t, &os.Stdout), capture(t, &os.Stderr)
+	restoreStdin := os.Stdin
+	os.Stdin = in
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				exit, ok := r.(exitPanic)
+				if !ok {
+					panic(r)
+				}
+				result.ExitCode = exit.code
+			}
+		}()
+
+		root.RootCmd.SetArgs(args)
+		if err := root.RootCmd.Execute(); err != nil {
+			result.ExitCode = 1
+		}
+	}()
+
+	os.Stdin = restoreStdin
+	result.Stdout = stdout()
+	result.Stderr = stderr()
+	return result
+}
+
+// capture redirects *f to a pipe until the returned function is called,
+// which restores it and returns what was written
+func capture(t *testing.T, f **os.File) func() string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+
+	original := *f
+	*f = w
+	return func() string {
+		*f = original
+		_ = w.Close()
+		return <-done
+	}
+}
+
+// resetFlags puts every flag of cmd and its subcommands back to its default
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else if err := f.Value.Set(f.DefValue); err != nil {
+			panic(fmt.Sprintf("failed to reset --%s: %v", f.Name, err))
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
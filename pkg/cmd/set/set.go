@@ -0,0 +1,189 @@
+package set
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fromFile    string
+	contentType string
+	tags        []string
+	notBefore   string
+	expires     string
+	disabled    bool
+)
+
+var SetCmd = &cobra.Command{
+	Use:   "set [vault-name] <secret-name> [value]",
+	Short: "Create a new secret version in Azure Key Vault",
+	Long: `Create a secret, or a new version of an existing secret, with its attributes.
+
+The value is taken from the argument, from --file, or from stdin when it is
+piped. An empty stdin is refused unless --file - is passed. Passing the value
+as an argument leaves it in your shell history, so prefer --file or stdin for
+real secrets.
+
+With a default vault set, two arguments are still read as the vault and the
+secret: passing the value as an argument always needs the vault in front of
+it.
+
+Dates accept RFC 3339 (2025-01-31T12:00:00Z), a plain date (2025-01-31) or a
+duration from now (720h).`,
+	Example: `  kv set my-vault api-key --file key.txt --tag env=prod --expires 2160h
+  generate-password | kv set my-vault db-password --content-type text/plain`,
+	Annotations: map[string]string{root.ValuesAnnotation: root.ValuesWithReveal},
+	Args:        cobra.RangeArgs(1, 3),
+	Run:         runSet,
+}
+
+func init() {
+	SetCmd.Flags().StringVarP(&fromFile, "file", "f", "", "Read secret value from file (- for stdin)")
+	SetCmd.Flags().StringVar(&contentType, "content-type", "", "Content type of the secret value")
+	SetCmd.Flags().StringArrayVarP(&tags, "tag", "t", nil, "Tag in key=value form (repeatable)")
+	SetCmd.Flags().StringVar(&notBefore, "not-before", "", "Date before which the secret cannot be used")
+	SetCmd.Flags().StringVar(&expires, "expires", "", "Expiration date of the secret")
+	SetCmd.Flags().BoolVar(&disabled, "disabled", false, "Create the version disabled")
+	root.RootCmd.AddCommand(SetCmd)
+}
+
+func runSet(cmd *cobra.Command, args []string) {
+	vaultName, rest, err := root.ResolveTarget(cmd, args, 2)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := rest[0]
+
+	// Two arguments are always the vault and the secret, so a value
+	// argument needs the vault in front of it even with a default vault
+	value, err := readValue(rest[1:])
+	if err != nil {
+		if len(args) == 2 && len(rest) == 1 && root.Profile.Vault != "" {
+			err = fmt.Errorf("%w (%q was read as the vault; to pass the value as an argument, name the vault too: kv set %s %s <value>)",
+				err, args[0], root.Profile.Vault, args[0])
+		}
+		root.ExitWithError(err)
+	}
+
+	attrs, err := buildAttributes()
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	secret, err := client.SetSecret(ctx, secretName, value, attrs)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	fmt.Fprintf(root.Progress(), "✓ Secret '%s' set (version: %s)\n", secretName, secret.Version)
+
+	if root.Output != "" {
+		if err := output.WriteOne(os.Stdout, root.Output, output.FromVersion(secretName, *secret, root.Reveal)); err != nil {
+			root.ExitWithError(fmt.Errorf("failed to write output: %w", err))
+		}
+	}
+}
+
+// readValue picks the secret value from the argument, --file or piped stdin
+func readValue(args []string) (string, error) {
+	if len(args) > 0 && fromFile != "" {
+		return "", fmt.Errorf("pass the value either as an argument or with --file, not both")
+	}
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	if fromFile == "-" || (fromFile == "" && !root.IsTerminal(os.Stdin)) {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		// An empty stdin that wasn't asked for is more likely a missing
+		// pipe than a request for an empty secret
+		if len(content) == 0 && fromFile != "-" {
+			return "", fmt.Errorf("stdin is empty: pass the value as an argument, with --file, or with --file - to set an empty value")
+		}
+		return string(content), nil
+	}
+
+	if fromFile != "" {
+		content, err := os.ReadFile(filepath.Clean(fromFile)) // #nosec G304 - User-specified file path for reading secret
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+		return string(content), nil
+	}
+
+	return "", fmt.Errorf("no value given: pass it as an argument, with --file, or on stdin")
+}
+
+// buildAttributes turns the flags into secret attributes
+func buildAttributes() (*keyvault.SecretAttributes, error) {
+	attrs := &keyvault.SecretAttributes{}
+
+	if contentType != "" {
+		attrs.ContentType = &contentType
+	}
+
+	if len(tags) > 0 {
+		attrs.Tags = make(map[string]string, len(tags))
+		for _, tag := range tags {
+			key, value, ok := strings.Cut(tag, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid tag %q: expected key=value", tag)
+			}
+			attrs.Tags[key] = value
+		}
+	}
+
+	if notBefore != "" {
+		t, err := parseTime(notBefore)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --not-before: %w", err)
+		}
+		attrs.NotBefore = &t
+	}
+
+	if expires != "" {
+		t, err := parseTime(expires)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --expires: %w", err)
+		}
+		attrs.ExpiresOn = &t
+	}
+
+	enabled := !disabled
+	attrs.Enabled = &enabled
+
+	return attrs, nil
+}
+
+// parseTime accepts RFC 3339, a plain date, or a duration from now
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.UTC(), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(d).UTC().Truncate(time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (2025-01-31, 2025-01-31T12:00:00Z) or duration (720h)", s)
+}
@@ -0,0 +1,235 @@
+package set
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2025-01-31T12:00:00Z", time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC), false},
+		{"2025-01-31T12:00:00+02:00", time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC), false},
+		{"2025-01-31", time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), false},
+		{"31/01/2025", time.Time{}, true},
+		{"soon", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseTime(tt.in)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("parseTime(%q) error = %v, want error: %t", tt.in, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("parseTime(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeDuration(t *testing.T) {
+	before := time.Now().Add(720 * time.Hour).Truncate(time.Second)
+	got, err := parseTime("720h")
+	if err != nil {
+		t.Fatalf("parseTime: %v", err)
+	}
+	after := time.Now().Add(720 * time.Hour)
+	if got.Before(before) || got.After(after) || got.Location() != time.UTC {
+		t.Errorf("parseTime(720h) = %v, want 720h from now in UTC", got)
+	}
+}
+
+func TestSet(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "value.txt")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		stdin     string
+		args      []string
+		wantValue string
+		wantTags  map[string]string
+		wantErr   string
+	}{
+		{name: "argument", args: []string{"db", "from-arg"}, wantValue: "from-arg"},
+		{name: "argument wins over stdin", stdin: "from-stdin", args: []string{"db", "from-arg"}, wantValue: "from-arg"},
+		{name: "file", stdin: "from-stdin", args: []string{"db", "--file", file}, wantValue: "from-file\n"},
+		{name: "piped stdin", stdin: "from-stdin", args: []string{"db"}, wantValue: "from-stdin"},
+		{name: "explicit stdin", stdin: "from-stdin", args: []string{"db", "-f", "-"}, wantValue: "from-stdin"},
+		{name: "explicit empty stdin", args: []string{"db", "-f", "-"}, wantValue: ""},
+		{name: "empty stdin", args: []string{"db"}, wantErr: "stdin is empty"},
+		{name: "argument and file", args: []string{"db", "v", "--file", file}, wantErr: "not both"},
+		{name: "missing file", args: []string{"db", "--file", file + ".missing"}, wantErr: "failed to read file"},
+		{
+			name:      "tags",
+			args:      []string{"db", "v", "--tag", "env=prod", "-t", "team=", "-t", "url=a=b"},
+			wantValue: "v",
+			wantTags:  map[string]string{"env": "prod", "team": "", "url": "a=b"},
+		},
+		{name: "tag without value", args: []string{"db", "v", "--tag", "env"}, wantErr: `invalid tag "env"`},
+		{name: "tag without key", args: []string{"db", "v", "--tag", "=prod"}, wantErr: `invalid tag "=prod"`},
+		{name: "bad date", args: []string{"db", "v", "--expires", "tomorrow"}, wantErr: "invalid --expires"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := keyvault.NewMemoryStore()
+			res := roottest.Run(t, store, tt.stdin, append([]string{"set", "vault"}, tt.args...)...)
+
+			if tt.wantErr != "" {
+				if res.ExitCode == 0 || !strings.Contains(res.Stderr, tt.wantErr) {
+					t.Errorf("exit code %d, stderr %q; want an error containing %q", res.ExitCode, res.Stderr, tt.wantErr)
+				}
+				if _, err := store.GetSecret(context.Background(), "db", ""); err == nil {
+					t.Error("secret was written despite the error")
+				}
+				return
+			}
+
+			if res.ExitCode != 0 {
+				t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+			}
+			got, err := store.GetSecret(context.Background(), "db", "")
+			if err != nil {
+				t.Fatalf("GetSecret: %v", err)
+			}
+			if got.Value != tt.wantValue {
+				t.Errorf("value = %q, want %q", got.Value, tt.wantValue)
+			}
+			if tt.wantTags != nil && !maps.Equal(got.Tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", got.Tags, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestSetAttributes(t *testing.T) {
+	store := keyvault.NewMemoryStore()
+	res := roottest.Run(t, store, "", "set", "vault", "db", "v",
+		"--content-type", "text/plain", "--not-before", "2025-01-01", "--expires", "2025-01-31T12:00:00Z", "--disabled")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+
+	versions, err := store.ListSecretVersions(context.Background(), "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := versions[0]
+	if v.Enabled || v.ContentType != "text/plain" {
+		t.Errorf("Enabled %t, ContentType %q; want a disabled text/plain version", v.Enabled, v.ContentType)
+	}
+	if v.NotBefore == nil || !v.NotBefore.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("NotBefore = %v", v.NotBefore)
+	}
+	if v.ExpiresOn == nil || !v.ExpiresOn.Equal(time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("ExpiresOn = %v", v.ExpiresOn)
+	}
+}
+
+func TestSetEnvOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  string
+		args     []string
+		wantExit int
+		wantOut  string
+	}{
+		{"without --reveal", "", []string{"-o", "env"}, 1, ""},
+		{"profile default without --reveal", "profiles:\n  default:\n    output: env\n", nil, 1, ""},
+		{"with --reveal", "", []string{"-o", "env", "--reveal"}, 0, "DB='v'\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			t.Setenv("KV_CONFIG", path)
+			if err := os.WriteFile(path, []byte(tt.profile), 0600); err != nil {
+				t.Fatal(err)
+			}
+			store := keyvault.NewMemoryStore()
+
+			res := roottest.Run(t, store, "", append([]string{"set", "vault", "db", "v"}, tt.args...)...)
+			if res.ExitCode != tt.wantExit {
+				t.Fatalf("exit code %d, want %d (stderr: %s)", res.ExitCode, tt.wantExit, res.Stderr)
+			}
+			if res.ExitCode != 0 {
+				// Refused before anything was written
+				if !strings.Contains(res.Stderr, "requires --reveal") || res.VaultURL != "" {
+					t.Errorf("stderr %q, opened %q; want a refusal before opening the vault", res.Stderr, res.VaultURL)
+				}
+				if _, err := store.GetSecret(context.Background(), "db", ""); err == nil {
+					t.Error("secret was written")
+				}
+				return
+			}
+			if !strings.HasSuffix(res.Stdout, tt.wantOut) {
+				t.Errorf("stdout = %q, want it to end with %q", res.Stdout, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestSetWithDefaultVault(t *testing.T) {
+	tests := []struct {
+		name      string
+		stdin     string
+		args      []string
+		wantExit  int
+		wantVault string
+		wantName  string
+		wantValue string
+	}{
+		{"secret and value is refused", "", []string{"db-password", "hunter2"}, 1, "", "", ""},
+		{"secret only", "hunter2", []string{"db-password"}, 0, "default-vault", "db-password", "hunter2"},
+		{"explicit vault with value", "", []string{"other-vault", "db-password", "hunter2"}, 0, "other-vault", "db-password", "hunter2"},
+		{"explicit vault with stdin", "hunter2", []string{"other-vault", "db-password"}, 0, "other-vault", "db-password", "hunter2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			t.Setenv("KV_CONFIG", path)
+			if err := os.WriteFile(path, []byte("profiles:\n  default:\n    vault: default-vault\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			store := keyvault.NewMemoryStore()
+
+			res := roottest.Run(t, store, tt.stdin, append([]string{"set"}, tt.args...)...)
+			if res.ExitCode != tt.wantExit {
+				t.Fatalf("exit code %d, want %d (stderr: %s)", res.ExitCode, tt.wantExit, res.Stderr)
+			}
+			if tt.wantExit != 0 {
+				if res.VaultURL != "" {
+					t.Errorf("opened %s", res.VaultURL)
+				}
+				if !strings.Contains(res.Stderr, `"db-password" was read as the vault; to pass the value as an argument, name the vault too: kv set default-vault db-password <value>`) {
+					t.Errorf("stderr = %q, want a hint to name the vault", res.Stderr)
+				}
+				if secrets, _ := store.ListSecrets(context.Background()); len(secrets) != 0 {
+					t.Errorf("secrets written: %+v", secrets)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(res.VaultURL, "https://"+tt.wantVault+".") {
+				t.Errorf("opened %s, want %s", res.VaultURL, tt.wantVault)
+			}
+			got, err := store.GetSecret(context.Background(), tt.wantName, "")
+			if err != nil || got.Value != tt.wantValue {
+				t.Errorf("GetSecret(%s) = %v, %v; want %q", tt.wantName, got, err, tt.wantValue)
+			}
+		})
+	}
+}
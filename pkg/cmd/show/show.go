@@ -3,8 +3,11 @@ package show
 import (
 	"context"
 	"fmt"
+	"os"
 
+	"github.com/bayhaqi/kv/internal/output"
 	"github.com/bayhaqi/kv/internal/tui"
+	"github.com/bayhaqi/kv/pkg/cmd/rollback"
 	"github.com/bayhaqi/kv/pkg/cmd/root"
 	"github.com/bayhaqi/kv/pkg/keyvault"
 	tea "github.com/charmbracelet/bubbletea"
@@ -12,11 +15,16 @@ import (
 )
 
 var ShowCmd = &cobra.Command{
-	Use:   "show <vault-name> <secret-name>",
+	Use:   "show [vault-name] <secret-name>",
 	Short: "Browse secret versions in Azure Key Vault",
-	Long:  `Browse different versions of a secret in Azure Key Vault using an interactive TUI.`,
-	Args:  cobra.ExactArgs(2),
-	Run:   runShow,
+	Long: `Browse different versions of a secret in Azure Key Vault using an interactive TUI.
+
+With --output the versions are printed instead (values only with --reveal).
+--output env prints the latest version. Without a terminal a plain table is
+printed.`,
+	Annotations: map[string]string{root.ValuesAnnotation: root.ValuesWithReveal},
+	Args:        cobra.RangeArgs(1, 2),
+	Run:         runShow,
 }
 
 func init() {
@@ -24,34 +32,106 @@ func init() {
 }
 
 func runShow(cmd *cobra.Command, args []string) {
-	vaultName := args[0]
-	secretName := args[1]
-
-	// Build vault URL from vault name
-	vaultURL := fmt.Sprintf("https://%s.vault.azure.net/", vaultName)
+	vaultName, args, err := root.ResolveTarget(cmd, args, 2)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
 
-	// Fetch secret versions
 	ctx := context.Background()
-	client, err := keyvault.NewClient(vaultURL)
+	client, err := root.OpenStore(vaultName)
 	if err != nil {
-		root.ExitWithError(fmt.Errorf("failed to create Key Vault client: %w", err))
+		root.ExitWithError(err)
+	}
+
+	// Fall back to a table when there is no terminal for the TUI
+	format := root.Output
+	if format == "" && !root.Interactive() {
+		format = output.Table
 	}
 
-	versions, err := client.ListSecretVersions(ctx, secretName)
+	if format != "" {
+		if err := printVersions(ctx, client, secretName, format); err != nil {
+			root.ExitWithError(err)
+		}
+		return
+	}
+
+	if err := Browse(ctx, client, secretName); err != nil {
+		root.ExitWithError(err)
+	}
+}
+
+// printVersions writes the versions of a secret in the given format,
+// fetching values only when they are revealed
+func printVersions(ctx context.Context, store keyvault.SecretStore, secretName, format string) error {
+	// env has one variable per secret, so it only gets the latest version
+	if format == output.Env {
+		latest, err := store.GetSecret(ctx, secretName, "")
+		if err != nil {
+			return fmt.Errorf("failed to get latest version: %w", err)
+		}
+		return output.WriteOne(os.Stdout, format, output.FromVersion(secretName, *latest, root.Reveal))
+	}
+
+	var versions []keyvault.SecretVersion
+	var err error
+	if root.Reveal {
+		versions, err = keyvault.ListSecretVersionsWithValues(ctx, store, secretName, nil)
+	} else {
+		versions, err = store.ListSecretVersions(ctx, secretName)
+	}
 	if err != nil {
-		root.ExitWithError(fmt.Errorf("failed to list secret versions: %w", err))
+		return fmt.Errorf("failed to list secret versions: %w", err)
+	}
+
+	return output.Write(os.Stdout, format, output.FromVersions(secretName, versions, root.Reveal))
+}
+
+// Browse lists the versions of a secret and opens the version browser
+func Browse(ctx context.Context, store keyvault.SecretStore, secretName string) error {
+	versions, err := store.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		return fmt.Errorf("failed to list secret versions: %w", err)
 	}
 
 	if len(versions) == 0 {
 		fmt.Println("No versions found for this secret.")
-		return
+		return nil
 	}
 
-	// Start TUI
-	model := tui.NewModel(versions, secretName)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	// Start TUI, returning to it after each action until the user quits
+	model := tui.NewModel(ctx, store, versions, secretName)
+	for {
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		finalModel, err := p.Run()
+		if err != nil {
+			return fmt.Errorf("TUI error: %w", err)
+		}
+
+		result := finalModel.(tui.Model)
+
+		var status string
+		switch result.Action() {
+		case tui.ActionRollback, tui.ActionRollbackWithMetadata:
+			target := result.Selected()
+			withMetadata := result.Action() == tui.ActionRollbackWithMetadata
+			created, err := rollback.Rollback(ctx, store, secretName, target, withMetadata, false)
+			switch {
+			case err != nil:
+				status = fmt.Sprintf("Error: %v", err)
+			case created != nil:
+				status = fmt.Sprintf("Rolled back to %s", keyvault.ShortVersion(target.Version))
+			default:
+				status = "Secret not updated"
+			}
+		default:
+			return nil
+		}
 
-	if _, err := p.Run(); err != nil {
-		root.ExitWithError(fmt.Errorf("TUI error: %w", err))
+		if refreshed, err := store.ListSecretVersions(ctx, secretName); err == nil {
+			versions = refreshed
+		}
+		model = result.Resume(versions, status)
 	}
 }
@@ -0,0 +1,99 @@
+package show
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+// newStore returns a store holding two versions of "db", an hour apart
+func newStore(t *testing.T) (*keyvault.MemoryStore, []string) {
+	t.Helper()
+
+	store := keyvault.NewMemoryStore()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i, value := range []string{"old-value", "new-value"} {
+		createdOn := created.Add(time.Duration(i) * time.Hour)
+		v, err := store.AddVersion("db", keyvault.SecretVersion{
+			Value:     value,
+			Enabled:   true,
+			CreatedOn: &createdOn,
+			Tags:      map[string]string{"env": "dev"},
+		})
+		if err != nil {
+			t.Fatalf("AddVersion: %v", err)
+		}
+		ids = append(ids, v.Version)
+	}
+	return store, ids
+}
+
+func TestShowTableWithoutTerminal(t *testing.T) {
+	store, ids := newStore(t)
+
+	res := roottest.Run(t, store, "", "show", "my-vault", "db")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+	if res.VaultURL != "https://my-vault.vault.azure.net/" {
+		t.Errorf("opened %q", res.VaultURL)
+	}
+
+	lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header and two versions:\n%s", len(lines), res.Stdout)
+	}
+	if !strings.HasPrefix(lines[0], "NAME") {
+		t.Errorf("missing header: %q", lines[0])
+	}
+	// Newest first, values redacted
+	if !strings.Contains(lines[1], keyvault.ShortVersion(ids[1])) || !strings.Contains(lines[2], keyvault.ShortVersion(ids[0])) {
+		t.Errorf("versions not newest first:\n%s", res.Stdout)
+	}
+	if strings.Contains(res.Stdout, "new-value") {
+		t.Errorf("value printed without --reveal:\n%s", res.Stdout)
+	}
+}
+
+func TestShowJSONReveal(t *testing.T) {
+	store, ids := newStore(t)
+
+	res := roottest.Run(t, store, "", "show", "my-vault", "db", "--output", "json", "--reveal")
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d, stderr: %s", res.ExitCode, res.Stderr)
+	}
+
+	var got []struct {
+		Version string            `json:"version"`
+		Value   *string           `json:"value"`
+		Enabled bool              `json:"enabled"`
+		Tags    map[string]string `json:"tags"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, res.Stdout)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d versions, want 2", len(got))
+	}
+	if got[0].Version != ids[1] || got[0].Value == nil || *got[0].Value != "new-value" {
+		t.Errorf("first entry = %+v, want latest version with its value", got[0])
+	}
+	if got[1].Tags["env"] != "dev" || !got[1].Enabled {
+		t.Errorf("second entry = %+v, want tags and enabled flag", got[1])
+	}
+}
+
+func TestShowMissingSecret(t *testing.T) {
+	store, _ := newStore(t)
+
+	res := roottest.Run(t, store, "", "show", "my-vault", "missing")
+	if res.ExitCode != root.ExitCodeNotFound {
+		t.Errorf("exit code %d, want %d; stderr: %s", res.ExitCode, root.ExitCodeNotFound, res.Stderr)
+	}
+}
@@ -0,0 +1,105 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/cmd/root"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"github.com/spf13/cobra"
+)
+
+var VersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Manage individual secret versions",
+	Long: `Manage individual versions of a secret.
+
+Disabling a version makes its value unreadable without deleting it, for
+example to kill a leaked credential while keeping the history. Versions can
+be given as their full ID or a unique prefix.`,
+}
+
+var enableCmd = &cobra.Command{
+	Use:   "enable [vault-name] <secret-name> <version>",
+	Short: "Enable a secret version",
+	Args:  cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		runSetEnabled(cmd, args, true)
+	},
+}
+
+var disableCmd = &cobra.Command{
+	Use:     "disable [vault-name] <secret-name> <version>",
+	Short:   "Disable a secret version",
+	Example: `  kv version disable my-vault api-key 1a2b3c4d`,
+	Args:    cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		runSetEnabled(cmd, args, false)
+	},
+}
+
+func init() {
+	VersionCmd.AddCommand(enableCmd, disableCmd)
+	root.RootCmd.AddCommand(VersionCmd)
+}
+
+func runSetEnabled(cmd *cobra.Command, args []string, enabled bool) {
+	vaultName, args, err := root.ResolveTarget(cmd, args, 3)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+	secretName := args[0]
+	versionID := args[1]
+
+	ctx := context.Background()
+	client, err := root.OpenStore(vaultName)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	versions, err := client.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		root.ExitWithError(fmt.Errorf("failed to list secret versions: %w", err))
+	}
+
+	target, err := keyvault.FindVersion(versions, versionID)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	updated, err := setEnabled(ctx, client, secretName, target, enabled)
+	if err != nil {
+		root.ExitWithError(err)
+	}
+
+	if root.Output != "" {
+		if err := output.WriteOne(os.Stdout, root.Output, output.FromVersion(secretName, *updated, false)); err != nil {
+			root.ExitWithError(fmt.Errorf("failed to write output: %w", err))
+		}
+	}
+}
+
+// setEnabled enables or disables a version, leaving its value and other
+// attributes alone. Versions already in the wanted state are not updated.
+func setEnabled(ctx context.Context, store keyvault.SecretStore, secretName string, target keyvault.SecretVersion, enabled bool) (*keyvault.SecretVersion, error) {
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	short := keyvault.ShortVersion(target.Version)
+
+	if target.Enabled == enabled {
+		fmt.Fprintf(root.Progress(), "Version %s of '%s' is already %s.\n", short, secretName, state)
+		return &target, nil
+	}
+
+	updated, err := store.UpdateSecretProperties(ctx, secretName, target.Version, keyvault.SecretAttributes{Enabled: &enabled})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update version %s: %w", short, err)
+	}
+
+	fmt.Fprintf(root.Progress(), "✓ Version %s of '%s' %s\n", short, secretName, state)
+	return updated, nil
+}
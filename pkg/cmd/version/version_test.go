@@ -0,0 +1,57 @@
+package version
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bayhaqi/kv/pkg/cmd/root/roottest"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+)
+
+func TestDisableEnvOutput(t *testing.T) {
+	tests := []struct {
+		name         string
+		profile      string
+		args         []string
+		wantExit     int
+		wantDisabled bool
+	}{
+		{"explicit env refused", "", []string{"-o", "env"}, 1, false},
+		{"profile env ignored", "profiles:\n  default:\n    output: env\n", nil, 0, true},
+		{"json", "", []string{"-o", "json"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			t.Setenv("KV_CONFIG", path)
+			if err := os.WriteFile(path, []byte(tt.profile), 0600); err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			store := keyvault.NewMemoryStore()
+			v, err := store.SetSecret(ctx, "db", "v", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := roottest.Run(t, store, "", append([]string{"version", "disable", "vault", "db", v.Version[:8]}, tt.args...)...)
+			if res.ExitCode != tt.wantExit {
+				t.Fatalf("exit code %d, want %d (stderr: %s)", res.ExitCode, tt.wantExit, res.Stderr)
+			}
+			if tt.wantExit != 0 && !strings.Contains(res.Stderr, "no secret value to show") {
+				t.Errorf("stderr = %q, want it to explain why env is refused", res.Stderr)
+			}
+
+			versions, err := store.ListSecretVersions(ctx, "db")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if disabled := !versions[0].Enabled; disabled != tt.wantDisabled {
+				t.Errorf("disabled = %t, want %t", disabled, tt.wantDisabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bayhaqi/kv/internal/output"
+	"github.com/bayhaqi/kv/pkg/keyvault"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is used when no profile has been chosen
+const DefaultProfile = "default"
+
+// Themes lists the accepted TUI themes
+var Themes = []string{"default", "plain"}
+
+// Profile holds the settings used for a set of vaults
+type Profile struct {
+	Vault    string `yaml:"vault,omitempty"`
+	Cloud    string `yaml:"cloud,omitempty"`
+	Auth     string `yaml:"auth,omitempty"`
+	Tenant   string `yaml:"tenant,omitempty"`
+	ClientID string `yaml:"clientId,omitempty"`
+	Output   string `yaml:"output,omitempty"`
+	Editor   string `yaml:"editor,omitempty"`
+	Theme    string `yaml:"theme,omitempty"`
+}
+
+// Alias is a short name for a secret in a vault, or for a vault alone
+// when Secret is empty
+type Alias struct {
+	Vault  string `yaml:"vault"`
+	Secret string `yaml:"secret,omitempty"`
+}
+
+func (a Alias) String() string {
+	if a.Secret == "" {
+		return a.Vault
+	}
+	return a.Vault + "/" + a.Secret
+}
+
+// Config is the contents of the config file
+type Config struct {
+	CurrentProfile string              `yaml:"currentProfile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+	Aliases        map[string]Alias    `yaml:"aliases,omitempty"`
+
+	path string
+}
+
+// Path returns the config file location: $KV_CONFIG, or kv/config.yaml in
+// the user config directory ($XDG_CONFIG_HOME or ~/.config on Linux)
+func Path() (string, error) {
+	if path := os.Getenv("KV_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "kv", "config.yaml"), nil
+}
+
+// Load reads the config file. A missing file is an empty config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{path: path}
+	data, err := os.ReadFile(path) // #nosec G304 - Config file path chosen by the user
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the config file, readable only by the user
+func (c *Config) Save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	data := buf.Bytes()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// File returns the path the config was loaded from
+func (c *Config) File() string {
+	return c.path
+}
+
+// ActiveProfile returns the name of the profile to use: name if set, then
+// the current profile, then DefaultProfile
+func (c *Config) ActiveProfile(name string) string {
+	switch {
+	case name != "":
+		return name
+	case c.CurrentProfile != "":
+		return c.CurrentProfile
+	default:
+		return DefaultProfile
+	}
+}
+
+// Profile returns a profile by name. Missing profiles are empty.
+func (c *Config) Profile(name string) Profile {
+	if p, ok := c.Profiles[name]; ok && p != nil {
+		return *p
+	}
+	return Profile{}
+}
+
+// ProfileNames returns the names of all profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set changes a setting of a profile, creating the profile if needed. An
+// empty value removes the setting.
+func (c *Config) Set(profile, key, value string) error {
+	p := c.Profile(profile)
+	field, err := p.field(key)
+	if err != nil {
+		return err
+	}
+	if err := checkSetting(key, value); err != nil {
+		return err
+	}
+	*field = value
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	c.Profiles[profile] = &p
+	return nil
+}
+
+// AddAlias adds or replaces an alias
+func (c *Config) AddAlias(name string, alias Alias) error {
+	if name == "" || strings.ContainsAny(name, " \t/") {
+		return fmt.Errorf("invalid alias name %q: it must not be empty or contain spaces or slashes", name)
+	}
+	if _, err := keyvault.VaultURL(alias.Vault, keyvault.AzurePublic); err != nil {
+		return err
+	}
+
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]Alias)
+	}
+	c.Aliases[name] = alias
+	return nil
+}
+
+// RemoveAlias deletes an alias
+func (c *Config) RemoveAlias(name string) error {
+	if _, ok := c.Aliases[name]; !ok {
+		return fmt.Errorf("alias %q does not exist", name)
+	}
+	delete(c.Aliases, name)
+	return nil
+}
+
+// AliasNames returns the names of all aliases, sorted
+func (c *Config) AliasNames() []string {
+	names := make([]string, 0, len(c.Aliases))
+	for name := range c.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Keys lists the settings a profile can hold
+func Keys() []string {
+	return []string{"vault", "cloud", "auth", "tenant", "client-id", "output", "editor", "theme"}
+}
+
+// Get returns a setting of the profile
+func (p Profile) Get(key string) (string, error) {
+	field, err := p.field(key)
+	if err != nil {
+		return "", err
+	}
+	return *field, nil
+}
+
+func (p *Profile) field(key string) (*string, error) {
+	switch key {
+	case "vault":
+		return &p.Vault, nil
+	case "cloud":
+		return &p.Cloud, nil
+	case "auth":
+		return &p.Auth, nil
+	case "tenant":
+		return &p.Tenant, nil
+	case "client-id":
+		return &p.ClientID, nil
+	case "output":
+		return &p.Output, nil
+	case "editor":
+		return &p.Editor, nil
+	case "theme":
+		return &p.Theme, nil
+	default:
+		return nil, fmt.Errorf("unknown setting %q (use %s)", key, strings.Join(Keys(), ", "))
+	}
+}
+
+// checkSetting rejects values the commands would fail on later
+func checkSetting(key, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch key {
+	case "cloud":
+		_, err := keyvault.LookupCloud(value)
+		return err
+	case "auth":
+		if !slices.Contains(keyvault.AuthMethods, value) {
+			return fmt.Errorf("unknown auth method %q (use %s)", value, strings.Join(keyvault.AuthMethods, ", "))
+		}
+	case "output":
+		return output.CheckFormat(value)
+	case "theme":
+		if !slices.Contains(Themes, value) {
+			return fmt.Errorf("unknown theme %q (use %s)", value, strings.Join(Themes, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useConfigFile points KV_CONFIG at a file in a temporary directory
+func useConfigFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kv", "config.yaml")
+	t.Setenv("KV_CONFIG", path)
+	return path
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	useConfigFile(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Profiles) != 0 || cfg.ActiveProfile("") != DefaultProfile {
+		t.Errorf("missing file loaded as %+v", cfg)
+	}
+	if p := cfg.Profile("anything"); p != (Profile{}) {
+		t.Errorf("missing profile = %+v, want empty", p)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := useConfigFile(t)
+
+	cfg, _ := Load()
+	for _, kv := range [][2]string{{"vault", "my-vault"}, {"cloud", "AzureUSGovernment"}, {"output", "json"}, {"theme", "plain"}} {
+		if err := cfg.Set("work", kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%s): %v", kv[0], err)
+		}
+	}
+	cfg.CurrentProfile = "work"
+	if err := cfg.AddAlias("db", Alias{Vault: "my-vault", Secret: "db-password"}); err != nil {
+		t.Fatalf("AddAlias: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("config not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("config permissions = %o, want 600", perm)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := Profile{Vault: "my-vault", Cloud: "AzureUSGovernment", Output: "json", Theme: "plain"}
+	if got := loaded.Profile(loaded.ActiveProfile("")); got != want {
+		t.Errorf("profile = %+v, want %+v", got, want)
+	}
+	if got := loaded.Aliases["db"]; got.String() != "my-vault/db-password" {
+		t.Errorf("alias db = %v", got)
+	}
+	if loaded.File() != path {
+		t.Errorf("File() = %q, want %q", loaded.File(), path)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := useConfigFile(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("profiles: [not, a, map]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil {
+		t.Error("Load accepted an invalid config file")
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key, value string
+		wantErr    bool
+	}{
+		{"vault", "my-vault", false},
+		{"cloud", "azurechina", false},
+		{"cloud", "mars", true},
+		{"auth", "cli", false},
+		{"auth", "password", true},
+		{"output", "yaml", false},
+		{"output", "xml", true},
+		{"theme", "default", false},
+		{"theme", "neon", true},
+		{"editor", "code --wait", false},
+		{"colour", "red", true},
+		{"theme", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := &Config{}
+			err := cfg.Set("p", tt.key, tt.value)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Set(%s, %q) error = %v, want error: %t", tt.key, tt.value, err, tt.wantErr)
+			}
+			if err != nil {
+				if _, ok := cfg.Profiles["p"]; ok {
+					t.Error("a rejected setting created the profile")
+				}
+				return
+			}
+			if got, _ := cfg.Profile("p").Get(tt.key); got != tt.value {
+				t.Errorf("Get(%s) = %q, want %q", tt.key, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestSetEmptyRemoves(t *testing.T) {
+	cfg := &Config{}
+	if err := cfg.Set("p", "vault", "my-vault"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Set("p", "vault", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Profile("p"); got != (Profile{}) {
+		t.Errorf("profile = %+v, want the vault removed", got)
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	tests := []struct {
+		current, flag, want string
+	}{
+		{"", "", DefaultProfile},
+		{"work", "", "work"},
+		{"work", "home", "home"},
+		{"", "home", "home"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{CurrentProfile: tt.current}
+		if got := cfg.ActiveProfile(tt.flag); got != tt.want {
+			t.Errorf("ActiveProfile(%q) with current %q = %q, want %q", tt.flag, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	cfg := &Config{}
+
+	invalid := []struct {
+		name  string
+		alias Alias
+	}{
+		{"", Alias{Vault: "my-vault"}},
+		{"has space", Alias{Vault: "my-vault"}},
+		{"has/slash", Alias{Vault: "my-vault"}},
+		{"badvault", Alias{Vault: "x"}},
+		{"badurl", Alias{Vault: "http://my-vault.vault.azure.net"}},
+	}
+	for _, tt := range invalid {
+		if err := cfg.AddAlias(tt.name, tt.alias); err == nil {
+			t.Errorf("AddAlias(%q, %v) accepted", tt.name, tt.alias)
+		}
+	}
+
+	for name, alias := range map[string]Alias{
+		"prod": {Vault: "prod-vault"},
+		"db":   {Vault: "https://prod-vault.vault.azure.net/", Secret: "db-password"},
+	} {
+		if err := cfg.AddAlias(name, alias); err != nil {
+			t.Fatalf("AddAlias(%q): %v", name, err)
+		}
+	}
+	if got := cfg.AliasNames(); !reflect.DeepEqual(got, []string{"db", "prod"}) {
+		t.Errorf("AliasNames = %v", got)
+	}
+	if got := cfg.Aliases["prod"].String(); got != "prod-vault" {
+		t.Errorf("vault alias String() = %q", got)
+	}
+
+	if err := cfg.RemoveAlias("prod"); err != nil {
+		t.Fatalf("RemoveAlias: %v", err)
+	}
+	if err := cfg.RemoveAlias("prod"); err == nil {
+		t.Error("removing a missing alias succeeded")
+	}
+	if got := cfg.AliasNames(); !reflect.DeepEqual(got, []string{"db"}) {
+		t.Errorf("AliasNames after remove = %v", got)
+	}
+}
@@ -3,10 +3,8 @@ package keyvault
 import (
 	"context"
 	"fmt"
-	"sort"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
 )
 
@@ -17,20 +15,72 @@ type Client struct {
 
 // SecretVersion represents a version of a secret
 type SecretVersion struct {
-	Version   string
-	Value     string
-	Enabled   bool
-	CreatedOn *time.Time
-	UpdatedOn *time.Time
-	ExpiresOn *time.Time
-	Tags      map[string]string
+	Version     string
+	Value       string
+	Enabled     bool
+	ContentType string
+	CreatedOn   *time.Time
+	UpdatedOn   *time.Time
+	NotBefore   *time.Time
+	ExpiresOn   *time.Time
+	Tags        map[string]string
+
+	// RecoveryLevel is the vault's deletion recovery level, such as
+	// "Recoverable+Purgeable". See Recoverable and Purgeable.
+	RecoveryLevel string
+
+	// ValueErr records why Value could not be fetched, if it was requested
+	ValueErr error
 }
 
-// NewClient creates a new Key Vault client
-func NewClient(vaultURL string) (*Client, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+// SecretProperties describes a secret in a vault, as reported for its latest version
+type SecretProperties struct {
+	Name        string
+	Enabled     bool
+	ContentType string
+	CreatedOn   *time.Time
+	UpdatedOn   *time.Time
+	ExpiresOn   *time.Time
+	Tags        map[string]string
+}
+
+// SecretAttributes are the optional attributes written with a secret.
+// Nil fields are left unset.
+type SecretAttributes struct {
+	ContentType *string
+	Tags        map[string]string
+	NotBefore   *time.Time
+	ExpiresOn   *time.Time
+	Enabled     *bool
+}
+
+// ClientOptions configures NewClient. The zero value uses the default
+// credential chain against the public cloud.
+type ClientOptions struct {
+	// Cloud selects the authority host used to authenticate
+	Cloud Cloud
+
+	// Auth is one of the Auth* methods
+	Auth string
+
+	// TenantID and ClientID override the tenant and the application or
+	// user-assigned managed identity to authenticate as
+	TenantID string
+	ClientID string
+
+	// TokenFile is the federated token file for workload identity
+	TokenFile string
+}
+
+// NewClient creates a new Key Vault client. opts may be nil.
+func NewClient(vaultURL string, opts *ClientOptions) (*Client, error) {
+	if opts == nil {
+		opts = &ClientOptions{}
+	}
+
+	cred, err := newCredential(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create credential: %w", err)
+		return nil, err
 	}
 
 	client, err := azsecrets.NewClient(vaultURL, cred, nil)
@@ -41,7 +91,8 @@ func NewClient(vaultURL string) (*Client, error) {
 	return &Client{client: client}, nil
 }
 
-// ListSecretVersions lists all versions of a secret
+// ListSecretVersions lists the properties of all versions of a secret.
+// Values are not fetched; use GetSecret to retrieve a version's value.
 func (c *Client) ListSecretVersions(ctx context.Context, secretName string) ([]SecretVersion, error) {
 	pager := c.client.NewListSecretPropertiesVersionsPager(secretName, nil)
 
@@ -49,7 +100,7 @@ func (c *Client) ListSecretVersions(ctx context.Context, secretName string) ([]S
 	for pager.More() {
 		page, err := pager.NextPage(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get page: %w", err)
+			return nil, fmt.Errorf("failed to get page: %w", mapError(err))
 		}
 
 		for _, props := range page.Value {
@@ -62,62 +113,142 @@ func (c *Client) ListSecretVersions(ctx context.Context, secretName string) ([]S
 				continue
 			}
 
-			// Fetch the actual secret value for this version
-			resp, err := c.client.GetSecret(ctx, secretName, version, nil)
-			if err != nil {
-				// If we can't get the secret value, still add it but without value
-				versions = append(versions, SecretVersion{
-					Version:   version,
-					Value:     fmt.Sprintf("Error fetching value: %v", err),
-					Enabled:   props.Attributes != nil && props.Attributes.Enabled != nil && *props.Attributes.Enabled,
-					CreatedOn: props.Attributes.Created,
-					UpdatedOn: props.Attributes.Updated,
-					ExpiresOn: props.Attributes.Expires,
-					Tags:      convertTags(props.Tags),
-				})
+			versions = append(versions, newSecretVersion(version, props.ContentType, props.Attributes, props.Tags))
+		}
+	}
+
+	sortVersionsNewestFirst(versions)
+
+	return versions, nil
+}
+
+// ListSecrets lists the properties of all secrets in the vault, sorted by name
+func (c *Client) ListSecrets(ctx context.Context) ([]SecretProperties, error) {
+	pager := c.client.NewListSecretPropertiesPager(nil)
+
+	var secrets []SecretProperties
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page: %w", mapError(err))
+		}
+
+		for _, props := range page.Value {
+			if props.ID == nil || props.ID.Name() == "" {
 				continue
 			}
 
-			value := ""
-			if resp.Value != nil {
-				value = *resp.Value
+			// Secrets backing certificates are managed through the certificate
+			if props.Managed != nil && *props.Managed {
+				continue
 			}
 
-			versions = append(versions, SecretVersion{
-				Version:   version,
-				Value:     value,
-				Enabled:   props.Attributes != nil && props.Attributes.Enabled != nil && *props.Attributes.Enabled,
-				CreatedOn: props.Attributes.Created,
-				UpdatedOn: props.Attributes.Updated,
-				ExpiresOn: props.Attributes.Expires,
-				Tags:      convertTags(props.Tags),
-			})
+			latest := newSecretVersion("", props.ContentType, props.Attributes, props.Tags)
+			secrets = append(secrets, newSecretProperties(props.ID.Name(), latest))
 		}
 	}
 
-	// Sort versions by creation date (newest first)
-	sort.Slice(versions, func(i, j int) bool {
-		if versions[i].CreatedOn == nil {
-			return false
-		}
-		if versions[j].CreatedOn == nil {
-			return true
-		}
-		return versions[i].CreatedOn.After(*versions[j].CreatedOn)
-	})
+	sortSecretsByName(secrets)
 
-	return versions, nil
+	return secrets, nil
+}
+
+// GetSecret fetches a single version of a secret including its value.
+// An empty version fetches the latest version.
+func (c *Client) GetSecret(ctx context.Context, secretName, version string) (*SecretVersion, error) {
+	resp, err := c.client.GetSecret(ctx, secretName, version, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", mapError(err))
+	}
+
+	if resp.ID != nil && resp.ID.Version() != "" {
+		version = resp.ID.Version()
+	}
+
+	secret := newSecretVersion(version, resp.ContentType, resp.Attributes, resp.Tags)
+	if resp.Value != nil {
+		secret.Value = *resp.Value
+	}
+	return &secret, nil
 }
 
-// SetSecret sets a secret value in the Key Vault
-func (c *Client) SetSecret(ctx context.Context, secretName, value string) error {
-	_, err := c.client.SetSecret(ctx, secretName, azsecrets.SetSecretParameters{
+// SetSecret sets a secret value in the Key Vault, creating a new version
+// with the given attributes. attrs may be nil.
+func (c *Client) SetSecret(ctx context.Context, secretName, value string, attrs *SecretAttributes) (*SecretVersion, error) {
+	params := azsecrets.SetSecretParameters{
 		Value: &value,
-	}, nil)
+	}
+	if attrs != nil {
+		params.ContentType = attrs.ContentType
+		params.Tags = toAzureTags(attrs.Tags)
+		params.SecretAttributes = &azsecrets.SecretAttributes{
+			Enabled:   attrs.Enabled,
+			Expires:   attrs.ExpiresOn,
+			NotBefore: attrs.NotBefore,
+		}
+	}
+
+	resp, err := c.client.SetSecret(ctx, secretName, params, nil)
 	if err != nil {
-		return fmt.Errorf("failed to set secret: %w", err)
+		return nil, fmt.Errorf("failed to set secret: %w", mapError(err))
+	}
+
+	version := ""
+	if resp.ID != nil {
+		version = resp.ID.Version()
+	}
+	secret := newSecretVersion(version, resp.ContentType, resp.Attributes, resp.Tags)
+	if resp.Value != nil {
+		secret.Value = *resp.Value
 	}
-	return nil
+	return &secret, nil
+}
+
+// UpdateSecretProperties changes the attributes of an existing version.
+// Nil fields and nil Tags are left unchanged.
+func (c *Client) UpdateSecretProperties(ctx context.Context, secretName, version string, attrs SecretAttributes) (*SecretVersion, error) {
+	params := azsecrets.UpdateSecretPropertiesParameters{
+		ContentType: attrs.ContentType,
+		Tags:        toAzureTags(attrs.Tags),
+		SecretAttributes: &azsecrets.SecretAttributes{
+			Enabled:   attrs.Enabled,
+			Expires:   attrs.ExpiresOn,
+			NotBefore: attrs.NotBefore,
+		},
+	}
+
+	resp, err := c.client.UpdateSecretProperties(ctx, secretName, version, params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update secret properties: %w", mapError(err))
+	}
+
+	if resp.ID != nil && resp.ID.Version() != "" {
+		version = resp.ID.Version()
+	}
+	secret := newSecretVersion(version, resp.ContentType, resp.Attributes, resp.Tags)
+	return &secret, nil
+}
+
+// newSecretVersion builds a SecretVersion from Azure SDK attributes and tags
+func newSecretVersion(version string, contentType *string, attrs *azsecrets.SecretAttributes, tags map[string]*string) SecretVersion {
+	secret := SecretVersion{
+		Version: version,
+		Tags:    convertTags(tags),
+	}
+	if contentType != nil {
+		secret.ContentType = *contentType
+	}
+	if attrs != nil {
+		secret.Enabled = attrs.Enabled != nil && *attrs.Enabled
+		secret.CreatedOn = attrs.Created
+		secret.UpdatedOn = attrs.Updated
+		secret.NotBefore = attrs.NotBefore
+		secret.ExpiresOn = attrs.Expires
+		if attrs.RecoveryLevel != nil {
+			secret.RecoveryLevel = *attrs.RecoveryLevel
+		}
+	}
+	return secret
 }
 
 // convertTags converts Azure SDK tags (map[string]*string) to map[string]string
@@ -134,3 +265,16 @@ func convertTags(azureTags map[string]*string) map[string]string {
 	}
 	return tags
 }
+
+// toAzureTags converts map[string]string to the Azure SDK's map[string]*string
+func toAzureTags(tags map[string]string) map[string]*string {
+	if tags == nil {
+		return nil
+	}
+
+	azureTags := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		azureTags[key] = &value
+	}
+	return azureTags
+}
@@ -0,0 +1,94 @@
+package keyvault
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// Cloud is an Azure cloud a vault can live in
+type Cloud struct {
+	Name string
+
+	// VaultSuffix is the DNS suffix of vaults in this cloud, e.g. ".vault.azure.net"
+	VaultSuffix string
+
+	// Config holds the authority host used for authentication
+	Config cloud.Configuration
+}
+
+// Known clouds
+var (
+	AzurePublic       = Cloud{Name: "AzurePublic", VaultSuffix: ".vault.azure.net", Config: cloud.AzurePublic}
+	AzureUSGovernment = Cloud{Name: "AzureUSGovernment", VaultSuffix: ".vault.usgovcloudapi.net", Config: cloud.AzureGovernment}
+	AzureChina        = Cloud{Name: "AzureChina", VaultSuffix: ".vault.azure.cn", Config: cloud.AzureChina}
+)
+
+var clouds = []Cloud{AzurePublic, AzureUSGovernment, AzureChina}
+
+// CloudNames returns the names accepted by LookupCloud
+func CloudNames() []string {
+	names := make([]string, len(clouds))
+	for i, c := range clouds {
+		names[i] = c.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupCloud finds a cloud by name, ignoring case
+func LookupCloud(name string) (Cloud, error) {
+	for _, c := range clouds {
+		if strings.EqualFold(c.Name, name) {
+			return c, nil
+		}
+	}
+	return Cloud{}, fmt.Errorf("unknown cloud %q (use %s)", name, strings.Join(CloudNames(), ", "))
+}
+
+// CloudForURL returns the cloud a vault URL belongs to, if its host has a
+// known vault suffix
+func CloudForURL(vaultURL string) (Cloud, bool) {
+	u, err := url.Parse(vaultURL)
+	if err != nil {
+		return Cloud{}, false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, c := range clouds {
+		if strings.HasSuffix(host, c.VaultSuffix) {
+			return c, true
+		}
+	}
+	return Cloud{}, false
+}
+
+// vaultName matches Key Vault's naming rules: 3-24 letters, digits and
+// dashes, starting with a letter and not ending with a dash
+var vaultName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{1,22}[a-zA-Z0-9]$`)
+
+// VaultURL resolves a vault argument to its URL. The argument can be a bare
+// vault name, which gets the cloud's suffix, a host name, or a full URL as
+// copied from the portal; any path in a URL is dropped.
+func VaultURL(vault string, c Cloud) (string, error) {
+	switch {
+	case strings.Contains(vault, "://"):
+		u, err := url.Parse(vault)
+		if err != nil {
+			return "", fmt.Errorf("invalid vault URL %q: %w", vault, err)
+		}
+		if u.Scheme != "https" || u.Host == "" {
+			return "", fmt.Errorf("invalid vault URL %q: expected https://<name>%s/", vault, c.VaultSuffix)
+		}
+		return "https://" + u.Host + "/", nil
+	case strings.Contains(vault, "."):
+		return "https://" + strings.TrimSuffix(vault, "/") + "/", nil
+	case vaultName.MatchString(vault):
+		return "https://" + vault + c.VaultSuffix + "/", nil
+	default:
+		return "", fmt.Errorf("invalid vault name %q: expected 3-24 letters, digits or dashes, or a vault URL", vault)
+	}
+}
@@ -0,0 +1,83 @@
+package keyvault
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVaultURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		vault   string
+		cloud   Cloud
+		want    string
+		wantErr string
+	}{
+		{"bare name", "my-vault", AzurePublic, "https://my-vault.vault.azure.net/", ""},
+		{"bare name in sovereign cloud", "my-vault", AzureChina, "https://my-vault.vault.azure.cn/", ""},
+		{"shortest name", "abc", AzurePublic, "https://abc.vault.azure.net/", ""},
+		{"host name", "my-vault.vault.usgovcloudapi.net", AzurePublic, "https://my-vault.vault.usgovcloudapi.net/", ""},
+		{"host name with slash", "my-vault.vault.azure.net/", AzurePublic, "https://my-vault.vault.azure.net/", ""},
+		{"URL", "https://my-vault.vault.azure.net", AzurePublic, "https://my-vault.vault.azure.net/", ""},
+		{"URL with path", "https://my-vault.vault.azure.net/secrets/db/abc123", AzurePublic, "https://my-vault.vault.azure.net/", ""},
+		{"http URL", "http://my-vault.vault.azure.net/", AzurePublic, "", "expected https://<name>.vault.azure.net/"},
+		{"URL without host", "https:///secrets", AzurePublic, "", "invalid vault URL"},
+		{"malformed URL", "https://my vault.vault.azure.net/%zz", AzurePublic, "", "invalid vault URL"},
+		{"too short", "ab", AzurePublic, "", "invalid vault name"},
+		{"too long", strings.Repeat("a", 25), AzurePublic, "", "invalid vault name"},
+		{"starts with a digit", "1vault", AzurePublic, "", "invalid vault name"},
+		{"ends with a dash", "my-vault-", AzurePublic, "", "invalid vault name"},
+		{"underscore", "my_vault", AzurePublic, "", "invalid vault name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VaultURL(tt.vault, tt.cloud)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VaultURL(%q) = %q, %v; want an error containing %q", tt.vault, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("VaultURL(%q) = %q, %v; want %q", tt.vault, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloudForURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		want   string
+		wantOK bool
+	}{
+		{"https://my-vault.vault.azure.net/", "AzurePublic", true},
+		{"https://my-vault.vault.usgovcloudapi.net/", "AzureUSGovernment", true},
+		{"https://MY-VAULT.VAULT.AZURE.CN/", "AzureChina", true},
+		{"https://my-vault.vault.azure.net:443/", "AzurePublic", true},
+		{"https://my-vault.example.com/", "", false},
+		{"https://vault.azure.net.example.com/", "", false},
+		{"://bad", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, ok := CloudForURL(tt.url)
+			if ok != tt.wantOK || got.Name != tt.want {
+				t.Errorf("CloudForURL(%q) = %q, %t; want %q, %t", tt.url, got.Name, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLookupCloud(t *testing.T) {
+	for _, name := range []string{"AzurePublic", "azureusgovernment", "AZURECHINA"} {
+		if c, err := LookupCloud(name); err != nil || !strings.EqualFold(c.Name, name) {
+			t.Errorf("LookupCloud(%q) = %q, %v", name, c.Name, err)
+		}
+	}
+
+	_, err := LookupCloud("AzureGermany")
+	if err == nil || !strings.Contains(err.Error(), "AzureChina, AzurePublic, AzureUSGovernment") {
+		t.Errorf("LookupCloud(AzureGermany) error = %v, want the known clouds listed", err)
+	}
+}
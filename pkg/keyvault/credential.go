@@ -0,0 +1,152 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Authentication methods for ClientOptions.Auth. The empty method uses
+// azidentity's default chain.
+const (
+	AuthDefault  = ""
+	AuthCLI      = "cli"
+	AuthDevice   = "device"
+	AuthMSI      = "msi"
+	AuthSP       = "sp"
+	AuthWorkload = "workload"
+	AuthEnv      = "env"
+)
+
+// AuthMethods lists the accepted authentication methods
+var AuthMethods = []string{AuthCLI, AuthDevice, AuthMSI, AuthSP, AuthWorkload, AuthEnv}
+
+// namedCredential tags authentication errors with the credential that
+// produced them, so a failure says which login was attempted
+type namedCredential struct {
+	name string
+	cred azcore.TokenCredential
+}
+
+func (c namedCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	token, err := c.cred.GetToken(ctx, opts)
+	if err != nil {
+		return token, fmt.Errorf("authentication with %s failed: %w", c.name, err)
+	}
+	return token, nil
+}
+
+// newCredential builds the credential selected by opts
+func newCredential(opts *ClientOptions) (azcore.TokenCredential, error) {
+	clientOpts := azcore.ClientOptions{}
+	if opts.Cloud.Name != "" {
+		clientOpts.Cloud = opts.Cloud.Config
+	}
+
+	var (
+		cred azcore.TokenCredential
+		name string
+		err  error
+	)
+	switch opts.Auth {
+	case AuthDefault:
+		if opts.ClientID != "" {
+			return nil, errors.New("a client ID needs an explicit auth method (msi, sp, workload or device)")
+		}
+		name = "the default credential chain (environment, workload identity, managed identity, Azure CLI)"
+		cred, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: clientOpts,
+			TenantID:      opts.TenantID,
+		})
+	case AuthCLI:
+		name = "Azure CLI credential"
+		cred, err = azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
+			TenantID: opts.TenantID,
+		})
+	case AuthDevice:
+		name = "device code credential"
+		cred, err = azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+			ClientOptions: clientOpts,
+			TenantID:      opts.TenantID,
+			ClientID:      opts.ClientID,
+			// Keep stdout free for command output
+			UserPrompt: func(ctx context.Context, msg azidentity.DeviceCodeMessage) error {
+				fmt.Fprintln(os.Stderr, msg.Message)
+				return nil
+			},
+		})
+	case AuthMSI:
+		name = "managed identity credential"
+		msiOpts := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOpts}
+		if opts.ClientID != "" {
+			name = fmt.Sprintf("managed identity credential (client ID %s)", opts.ClientID)
+			msiOpts.ID = azidentity.ClientID(opts.ClientID)
+		}
+		cred, err = azidentity.NewManagedIdentityCredential(msiOpts)
+	case AuthSP:
+		name = "service principal credential"
+		cred, err = newClientSecretCredential(opts, clientOpts)
+	case AuthWorkload:
+		name = "workload identity credential"
+		cred, err = azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOpts,
+			TenantID:      opts.TenantID,
+			ClientID:      opts.ClientID,
+			TokenFilePath: opts.TokenFile,
+		})
+	case AuthEnv:
+		name = "environment credential"
+		cred, err = azidentity.NewEnvironmentCredential(&azidentity.EnvironmentCredentialOptions{
+			ClientOptions: clientOpts,
+		})
+	default:
+		return nil, fmt.Errorf("unknown auth method %q (use %s)", opts.Auth, strings.Join(AuthMethods, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", name, err)
+	}
+
+	return namedCredential{name: name, cred: cred}, nil
+}
+
+// newClientSecretCredential builds a service principal credential. The
+// secret is only read from AZURE_CLIENT_SECRET so it never appears in
+// shell history; tenant and client ID fall back to the usual variables.
+func newClientSecretCredential(opts *ClientOptions, clientOpts azcore.ClientOptions) (azcore.TokenCredential, error) {
+	tenantID := firstNonEmpty(opts.TenantID, os.Getenv("AZURE_TENANT_ID"))
+	clientID := firstNonEmpty(opts.ClientID, os.Getenv("AZURE_CLIENT_ID"))
+	secret := os.Getenv("AZURE_CLIENT_SECRET")
+
+	var missing []string
+	if tenantID == "" {
+		missing = append(missing, "tenant (--tenant or AZURE_TENANT_ID)")
+	}
+	if clientID == "" {
+		missing = append(missing, "client ID (--client-id or AZURE_CLIENT_ID)")
+	}
+	if secret == "" {
+		missing = append(missing, "secret (AZURE_CLIENT_SECRET)")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	return azidentity.NewClientSecretCredential(tenantID, clientID, secret, &azidentity.ClientSecretCredentialOptions{
+		ClientOptions: clientOpts,
+	})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// clearAzureEnv hides the caller's Azure environment from newCredential.
+// The variables are unset, not emptied, since azidentity checks for them
+// with LookupEnv; t.Setenv restores them afterwards.
+func clearAzureEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"AZURE_TENANT_ID", "AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET",
+		"AZURE_CLIENT_CERTIFICATE_PATH", "AZURE_USERNAME", "AZURE_PASSWORD",
+		"AZURE_FEDERATED_TOKEN_FILE",
+	} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func TestNewCredential(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ClientOptions
+		env      map[string]string
+		wantErr  string
+		wantName string
+	}{
+		{
+			name:    "unknown method",
+			opts:    ClientOptions{Auth: "password"},
+			wantErr: `unknown auth method "password" (use cli, device, msi, sp, workload, env)`,
+		},
+		{
+			name:    "default with client ID",
+			opts:    ClientOptions{ClientID: "app"},
+			wantErr: "a client ID needs an explicit auth method",
+		},
+		{
+			name:    "sp without anything",
+			opts:    ClientOptions{Auth: AuthSP},
+			wantErr: "missing tenant (--tenant or AZURE_TENANT_ID), client ID (--client-id or AZURE_CLIENT_ID), secret (AZURE_CLIENT_SECRET)",
+		},
+		{
+			name:    "sp without secret",
+			opts:    ClientOptions{Auth: AuthSP, TenantID: "tenant", ClientID: "app"},
+			wantErr: "failed to create service principal credential: missing secret (AZURE_CLIENT_SECRET)",
+		},
+		{
+			name:    "sp without tenant",
+			opts:    ClientOptions{Auth: AuthSP, ClientID: "app"},
+			env:     map[string]string{"AZURE_CLIENT_SECRET": "s3cret"},
+			wantErr: "missing tenant (--tenant or AZURE_TENANT_ID)",
+		},
+		{
+			name:     "sp from flags and environment",
+			opts:     ClientOptions{Auth: AuthSP, ClientID: "app"},
+			env:      map[string]string{"AZURE_TENANT_ID": "tenant", "AZURE_CLIENT_SECRET": "s3cret"},
+			wantName: "service principal credential",
+		},
+		{
+			name:    "workload without token file",
+			opts:    ClientOptions{Auth: AuthWorkload, TenantID: "tenant", ClientID: "app"},
+			wantErr: "failed to create workload identity credential",
+		},
+		{
+			name:     "msi with client ID",
+			opts:     ClientOptions{Auth: AuthMSI, ClientID: "app"},
+			wantName: "managed identity credential (client ID app)",
+		},
+		{
+			name:     "cli",
+			opts:     ClientOptions{Auth: AuthCLI, Cloud: AzureChina},
+			wantName: "Azure CLI credential",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearAzureEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cred, err := newCredential(&tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newCredential error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newCredential: %v", err)
+			}
+			named, ok := cred.(namedCredential)
+			if !ok || named.name != tt.wantName {
+				t.Errorf("credential = %#v, want one named %q", cred, tt.wantName)
+			}
+		})
+	}
+}
+
+type failingCredential struct {
+	err error
+}
+
+func (c failingCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{}, c.err
+}
+
+func TestNamedCredentialWrapsErrors(t *testing.T) {
+	cause := errors.New("AADSTS700016: application not found")
+	cred := namedCredential{name: "service principal credential", cred: failingCredential{err: cause}}
+
+	_, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{})
+	if !errors.Is(err, cause) {
+		t.Errorf("error = %v, want it to wrap the cause", err)
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "authentication with service principal credential failed: ") {
+		t.Errorf("error = %v, want it to name the credential", err)
+	}
+
+	token, err := namedCredential{name: "cli", cred: fakeCredential{}}.GetToken(context.Background(), policy.TokenRequestOptions{})
+	if err != nil || token.Token != "token" {
+		t.Errorf("GetToken = %q, %v; want the wrapped credential's token", token.Token, err)
+	}
+}
@@ -0,0 +1,200 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// DeletedSecret describes a soft-deleted secret, which can be recovered
+// until its scheduled purge date
+type DeletedSecret struct {
+	Name               string
+	RecoveryID         string
+	RecoveryLevel      string
+	ContentType        string
+	DeletedOn          *time.Time
+	ScheduledPurgeDate *time.Time
+	Tags               map[string]string
+}
+
+// Recoverable reports whether a vault's recovery level keeps deleted
+// secrets around for recovery, that is whether soft delete is enabled
+func Recoverable(recoveryLevel string) bool {
+	return strings.Contains(recoveryLevel, "Recoverable")
+}
+
+// Purgeable reports whether deleted secrets can be purged before their
+// scheduled purge date. Vaults with purge protection are not purgeable.
+func Purgeable(recoveryLevel string) bool {
+	return strings.Contains(recoveryLevel, "Purgeable")
+}
+
+// Key Vault deletes, recovers and purges secrets in the background. These
+// control how long the client waits for the change to become visible.
+const (
+	pollInterval = 2 * time.Second
+	pollTimeout  = 5 * time.Minute
+)
+
+// DeleteSecret deletes all versions of a secret and waits until the
+// deleted secret can be found. Without soft delete the secret is gone for
+// good and nothing is waited for.
+func (c *Client) DeleteSecret(ctx context.Context, secretName string) (*DeletedSecret, error) {
+	resp, err := c.client.DeleteSecret(ctx, secretName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete secret: %w", mapError(err))
+	}
+
+	deleted := newDeletedSecret(secretName, resp.DeletedSecret.Attributes, resp.ContentType, resp.Tags, resp.RecoveryID, resp.DeletedDate, resp.ScheduledPurgeDate)
+	if !Recoverable(deleted.RecoveryLevel) {
+		return &deleted, nil
+	}
+
+	err = waitUntil(ctx, "the deletion of "+secretName, func(ctx context.Context) (bool, error) {
+		_, err := c.client.GetDeletedSecret(ctx, secretName, nil)
+		return deletedSecretState(err, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// ListDeletedSecrets lists the soft-deleted secrets in the vault, sorted by name
+func (c *Client) ListDeletedSecrets(ctx context.Context) ([]DeletedSecret, error) {
+	pager := c.client.NewListDeletedSecretPropertiesPager(nil)
+
+	var secrets []DeletedSecret
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get page: %w", mapError(err))
+		}
+
+		for _, props := range page.Value {
+			if props.ID == nil || props.ID.Name() == "" {
+				continue
+			}
+
+			// Secrets backing certificates are managed through the certificate
+			if props.Managed != nil && *props.Managed {
+				continue
+			}
+
+			secrets = append(secrets, newDeletedSecret(props.ID.Name(), props.Attributes, props.ContentType, props.Tags, props.RecoveryID, props.DeletedDate, props.ScheduledPurgeDate))
+		}
+	}
+
+	sortDeletedByName(secrets)
+
+	return secrets, nil
+}
+
+// RecoverDeletedSecret restores a soft-deleted secret with all its
+// versions and waits until it can be read again. The latest version is
+// returned without its value.
+func (c *Client) RecoverDeletedSecret(ctx context.Context, secretName string) (*SecretVersion, error) {
+	resp, err := c.client.RecoverDeletedSecret(ctx, secretName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover secret: %w", mapError(err))
+	}
+
+	err = waitUntil(ctx, "the recovery of "+secretName, func(ctx context.Context) (bool, error) {
+		_, err := c.client.GetSecret(ctx, secretName, "", nil)
+		err = mapError(err)
+		switch {
+		case err == nil, errors.Is(err, ErrSecretDisabled):
+			return true, nil
+		case errors.Is(err, ErrSecretNotFound):
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	version := ""
+	if resp.ID != nil {
+		version = resp.ID.Version()
+	}
+	secret := newSecretVersion(version, resp.ContentType, resp.Attributes, resp.Tags)
+	return &secret, nil
+}
+
+// PurgeDeletedSecret permanently deletes a soft-deleted secret and waits
+// until it is gone. Vaults with purge protection refuse this.
+func (c *Client) PurgeDeletedSecret(ctx context.Context, secretName string) error {
+	if _, err := c.client.PurgeDeletedSecret(ctx, secretName, nil); err != nil {
+		return fmt.Errorf("failed to purge secret: %w", mapError(err))
+	}
+
+	return waitUntil(ctx, "the purge of "+secretName, func(ctx context.Context) (bool, error) {
+		_, err := c.client.GetDeletedSecret(ctx, secretName, nil)
+		return deletedSecretState(err, false)
+	})
+}
+
+// deletedSecretState turns the result of looking up a deleted secret into
+// whether it is in the wanted state: present or gone
+func deletedSecretState(err error, wantPresent bool) (bool, error) {
+	err = mapError(err)
+	switch {
+	case err == nil:
+		return wantPresent, nil
+	case errors.Is(err, ErrSecretNotFound):
+		return !wantPresent, nil
+	default:
+		return false, err
+	}
+}
+
+// waitUntil polls done until it reports true, an error occurs or
+// pollTimeout passes. what names the operation in the timeout error.
+func waitUntil(ctx context.Context, what string, done func(context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, pollTimeout)
+	defer cancel()
+
+	for {
+		ok, err := done(ctx)
+		if err != nil {
+			return fmt.Errorf("failed waiting for %s: %w", what, err)
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s: %w", what, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// newDeletedSecret builds a DeletedSecret from the Azure SDK fields shared
+// by deleted secrets and their list items
+func newDeletedSecret(name string, attrs *azsecrets.SecretAttributes, contentType *string, tags map[string]*string, recoveryID *string, deletedOn, purgeOn *time.Time) DeletedSecret {
+	deleted := DeletedSecret{
+		Name:               name,
+		DeletedOn:          deletedOn,
+		ScheduledPurgeDate: purgeOn,
+		Tags:               convertTags(tags),
+	}
+	if contentType != nil {
+		deleted.ContentType = *contentType
+	}
+	if recoveryID != nil {
+		deleted.RecoveryID = *recoveryID
+	}
+	if attrs != nil && attrs.RecoveryLevel != nil {
+		deleted.RecoveryLevel = *attrs.RecoveryLevel
+	}
+	return deleted
+}
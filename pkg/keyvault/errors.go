@@ -0,0 +1,123 @@
+package keyvault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+var (
+	// ErrSecretNotFound is returned when a secret or secret version does not exist
+	ErrSecretNotFound = errors.New("secret not found")
+
+	// ErrForbidden is returned when the caller is not allowed to perform the operation
+	ErrForbidden = errors.New("access denied")
+
+	// ErrSecretDisabled is returned when reading the value of a disabled secret version
+	ErrSecretDisabled = errors.New("secret version is disabled")
+
+	// ErrThrottled is returned when Key Vault rejects a request with 429 Too Many Requests
+	ErrThrottled = errors.New("request throttled by Key Vault")
+
+	// ErrConflict is returned when a secret's state prevents the operation, such
+	// as writing to a deleted secret or recovering over a live one
+	ErrConflict = errors.New("conflict with the current state of the secret")
+)
+
+// throttledError wraps a 429 response together with the server's Retry-After hint
+type throttledError struct {
+	retryAfter time.Duration
+	err        error
+}
+
+func (e *throttledError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrThrottled, e.err)
+}
+
+func (e *throttledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+func (e *throttledError) Unwrap() error {
+	return e.err
+}
+
+// retryAfter returns the server's Retry-After hint for a throttled error, or zero
+func retryAfter(err error) time.Duration {
+	var throttled *throttledError
+	if errors.As(err, &throttled) {
+		return throttled.retryAfter
+	}
+	return 0
+}
+
+// mapError translates Key Vault HTTP errors into the package's sentinel errors
+// so callers can use errors.Is without depending on the Azure SDK
+func mapError(err error) error {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return err
+	}
+
+	switch respErr.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrSecretNotFound, err)
+	case http.StatusForbidden:
+		if isSecretDisabled(respErr) {
+			return fmt.Errorf("%w: %w", ErrSecretDisabled, err)
+		}
+		return fmt.Errorf("%w: %w", ErrForbidden, err)
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case http.StatusTooManyRequests:
+		return &throttledError{retryAfter: parseRetryAfter(respErr.RawResponse), err: err}
+	}
+	return err
+}
+
+// isSecretDisabled reports whether a 403 is Key Vault refusing to read a
+// disabled version, which it reports with an inner SecretDisabled code
+// under the outer Forbidden code
+func isSecretDisabled(respErr *azcore.ResponseError) bool {
+	const code = "SecretDisabled"
+	if respErr.ErrorCode == code {
+		return true
+	}
+	if respErr.RawResponse == nil {
+		return false
+	}
+
+	body, err := runtime.Payload(respErr.RawResponse)
+	if err != nil {
+		return false
+	}
+	var payload struct {
+		Error struct {
+			InnerError struct {
+				Code string `json:"code"`
+			} `json:"innererror"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return false
+	}
+	return payload.Error.InnerError.Code == code
+}
+
+// parseRetryAfter reads the Retry-After header, which Key Vault sends in seconds
+func parseRetryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
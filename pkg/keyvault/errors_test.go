@@ -0,0 +1,103 @@
+package keyvault
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// responseError builds the error the SDK returns for an HTTP response
+func responseError(status int, header http.Header, body string) error {
+	if header == nil {
+		header = http.Header{}
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://myvault.vault.azure.net/secrets/db", nil)
+	return runtime.NewResponseError(&http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	})
+}
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			"not found",
+			responseError(http.StatusNotFound, nil, `{"error":{"code":"SecretNotFound","message":"A secret with (name/id) db was not found in this key vault."}}`),
+			ErrSecretNotFound,
+		},
+		{
+			"disabled inner code",
+			responseError(http.StatusForbidden, nil, `{"error":{"code":"Forbidden","message":"Operation get is not allowed on a disabled secret.","innererror":{"code":"SecretDisabled"}}}`),
+			ErrSecretDisabled,
+		},
+		{
+			"disabled error code header",
+			responseError(http.StatusForbidden, http.Header{"X-Ms-Error-Code": {"SecretDisabled"}}, ""),
+			ErrSecretDisabled,
+		},
+		{
+			"forbidden mentioning SecretDisabled in its message",
+			responseError(http.StatusForbidden, nil, `{"error":{"code":"Forbidden","message":"Caller lacks get on SecretDisabled","innererror":{"code":"AccessDenied"}}}`),
+			ErrForbidden,
+		},
+		{
+			"forbidden without body",
+			responseError(http.StatusForbidden, nil, ""),
+			ErrForbidden,
+		},
+		{
+			"conflict",
+			responseError(http.StatusConflict, nil, `{"error":{"code":"Conflict","message":"Secret db is currently in a deleted but recoverable state"}}`),
+			ErrConflict,
+		},
+		{
+			"throttled",
+			responseError(http.StatusTooManyRequests, nil, ""),
+			ErrThrottled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapError(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("mapError = %v, want %v", got, tt.want)
+			}
+			for _, other := range []error{ErrSecretNotFound, ErrSecretDisabled, ErrForbidden, ErrThrottled, ErrConflict} {
+				if other != tt.want && errors.Is(got, other) {
+					t.Errorf("mapError = %v, also matches %v", got, other)
+				}
+			}
+		})
+	}
+}
+
+func TestMapErrorPassesOtherErrorsThrough(t *testing.T) {
+	plain := fmt.Errorf("dial tcp: connection refused")
+	if got := mapError(plain); got != plain {
+		t.Errorf("mapError = %v, want the error unchanged", got)
+	}
+	badRequest := responseError(http.StatusBadRequest, nil, `{"error":{"code":"BadParameter"}}`)
+	if got := mapError(badRequest); got != badRequest {
+		t.Errorf("mapError = %v, want the error unchanged", got)
+	}
+}
+
+func TestMapErrorRetryAfter(t *testing.T) {
+	err := mapError(responseError(http.StatusTooManyRequests, http.Header{"Retry-After": {"7"}}, ""))
+	if got := retryAfter(err); got != 7*time.Second {
+		t.Errorf("retryAfter = %v, want 7s", got)
+	}
+}
@@ -0,0 +1,224 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	// DefaultFetchConcurrency is the number of values fetched at once by default
+	DefaultFetchConcurrency = 8
+
+	// DefaultFetchRetries is how many times a throttled fetch is retried by default
+	DefaultFetchRetries = 5
+
+	defaultInitialBackoff = 500 * time.Millisecond
+	maxBackoff            = 30 * time.Second
+)
+
+// poolRetryOptions are the SDK's default retry options without 429, so a
+// throttled fetch is only retried by fetchWithBackoff. Otherwise the SDK
+// retries each request itself and the two stack.
+var poolRetryOptions = policy.RetryOptions{
+	StatusCodes: []int{
+		http.StatusRequestTimeout,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// FetchOptions configures how FetchValues retrieves version values.
+// Zero fields fall back to the package defaults.
+type FetchOptions struct {
+	// Concurrency is the maximum number of values fetched at once
+	Concurrency int
+
+	// MaxRetries is how many times a throttled fetch is retried before
+	// giving up. Nil uses DefaultFetchRetries; zero turns retries off. The
+	// SDK does not retry these fetches on 429 itself.
+	MaxRetries *int
+
+	// InitialBackoff is the first delay after a throttled response. It doubles
+	// on every retry unless the server sends a longer Retry-After.
+	InitialBackoff time.Duration
+}
+
+func (o *FetchOptions) withDefaults() FetchOptions {
+	opts := FetchOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultFetchConcurrency
+	}
+	if opts.MaxRetries == nil {
+		retries := DefaultFetchRetries
+		opts.MaxRetries = &retries
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultInitialBackoff
+	}
+	return opts
+}
+
+// ListSecretVersionsWithValues lists all versions of a secret, newest first,
+// and fetches every value in parallel. Versions whose value could not be
+// fetched are still returned, with ValueErr set.
+func ListSecretVersionsWithValues(ctx context.Context, store SecretStore, secretName string, opts *FetchOptions) ([]SecretVersion, error) {
+	versions, err := store.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := FetchValues(ctx, store, secretName, versions, opts); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// FetchValues fills in Value for each version using a bounded worker pool.
+// The order of versions is preserved. A failure to fetch one version is
+// recorded in its ValueErr; only cancellation of ctx is returned as an error.
+func FetchValues(ctx context.Context, store SecretStore, secretName string, versions []SecretVersion, opts *FetchOptions) error {
+	o := opts.withDefaults()
+	gate := &throttleGate{}
+
+	return forEach(ctx, len(versions), o.Concurrency, func(i int) {
+		secret, err := fetchWithBackoff(ctx, store, secretName, versions[i].Version, o, gate)
+		if err != nil {
+			versions[i].Value, versions[i].ValueErr = "", err
+			return
+		}
+		versions[i].Value, versions[i].ValueErr = secret.Value, nil
+	})
+}
+
+// FetchLatest fetches the latest version of each of the named secrets,
+// including its value, with the same worker pool and backoff as
+// FetchValues. The result has one entry per name, in order; a failure to
+// fetch one secret leaves only its ValueErr set.
+func FetchLatest(ctx context.Context, store SecretStore, names []string, opts *FetchOptions) ([]SecretVersion, error) {
+	o := opts.withDefaults()
+	gate := &throttleGate{}
+
+	latest := make([]SecretVersion, len(names))
+	err := forEach(ctx, len(names), o.Concurrency, func(i int) {
+		secret, err := fetchWithBackoff(ctx, store, names[i], "", o, gate)
+		if err != nil {
+			latest[i].ValueErr = err
+			return
+		}
+		latest[i] = *secret
+	})
+	if err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
+
+// forEach calls fn for every index below n on at most concurrency workers.
+// It stops handing out indexes once ctx is canceled and returns ctx.Err().
+func forEach(ctx context.Context, n, concurrency int, fn func(i int)) error {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < min(concurrency, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// fetchWithBackoff fetches a single value, backing off and retrying while
+// Key Vault responds with 429
+func fetchWithBackoff(ctx context.Context, store SecretStore, secretName, version string, o FetchOptions, gate *throttleGate) (*SecretVersion, error) {
+	backoff := o.InitialBackoff
+	ctx = policy.WithRetryOptions(ctx, poolRetryOptions)
+
+	for attempt := 0; ; attempt++ {
+		if err := gate.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		secret, err := store.GetSecret(ctx, secretName, version)
+		if err == nil {
+			return secret, nil
+		}
+		if !errors.Is(err, ErrThrottled) || attempt >= *o.MaxRetries {
+			return nil, err
+		}
+
+		delay := backoff + time.Duration(rand.Int64N(int64(backoff)/2+1)) // #nosec G404 - jitter does not need a secure source
+		if hint := retryAfter(err); hint > delay {
+			delay = hint
+		}
+		gate.pause(delay)
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// throttleGate pauses all workers of a pool after any of them is throttled,
+// so the pool as a whole backs off instead of only the unlucky worker
+type throttleGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (g *throttleGate) pause(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(g.until) {
+		g.until = until
+	}
+}
+
+func (g *throttleGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	delay := time.Until(g.until)
+	g.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for throttling to clear: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,309 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// scriptedStore wraps a MemoryStore, failing GetSecret for chosen versions
+// and recording how many fetches ran at once. Fetches of the latest version
+// are keyed by the secret name instead.
+type scriptedStore struct {
+	*MemoryStore
+
+	mu        sync.Mutex
+	throttles map[string]int   // throttled responses left per version
+	failures  map[string]error // permanent failures per version
+	calls     map[string]int
+	inFlight  int
+	maxFlight int
+}
+
+func newScriptedStore() *scriptedStore {
+	return &scriptedStore{
+		MemoryStore: NewMemoryStore(),
+		throttles:   make(map[string]int),
+		failures:    make(map[string]error),
+		calls:       make(map[string]int),
+	}
+}
+
+func (s *scriptedStore) GetSecret(ctx context.Context, name, version string) (*SecretVersion, error) {
+	key := version
+	if key == "" {
+		key = name
+	}
+
+	s.mu.Lock()
+	s.calls[key]++
+	s.inFlight++
+	s.maxFlight = max(s.maxFlight, s.inFlight)
+	throttled := s.throttles[key] > 0
+	if throttled {
+		s.throttles[key]--
+	}
+	failure := s.failures[key]
+	s.mu.Unlock()
+
+	// Give other workers a chance to overlap
+	time.Sleep(time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	if throttled {
+		return nil, &throttledError{err: errors.New("429 Too Many Requests")}
+	}
+	if failure != nil {
+		return nil, failure
+	}
+	return s.MemoryStore.GetSecret(ctx, name, version)
+}
+
+// addVersions stores n versions of "db" with values v0..v(n-1)
+func addVersions(t *testing.T, s *scriptedStore, n int) []string {
+	t.Helper()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := make([]string, n)
+	for i := range n {
+		created := start.Add(time.Duration(i) * time.Minute)
+		v, err := s.AddVersion("db", SecretVersion{Value: fmt.Sprintf("v%d", i), Enabled: true, CreatedOn: &created})
+		if err != nil {
+			t.Fatalf("AddVersion: %v", err)
+		}
+		ids[i] = v.Version
+	}
+	return ids
+}
+
+func retries(n int) *int {
+	return &n
+}
+
+func TestListSecretVersionsWithValues(t *testing.T) {
+	s := newScriptedStore()
+	ids := addVersions(t, s, 20)
+	broken := errors.New("boom")
+	s.failures[ids[3]] = broken
+
+	versions, err := ListSecretVersionsWithValues(context.Background(), s, "db", &FetchOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("ListSecretVersionsWithValues: %v", err)
+	}
+	if len(versions) != 20 {
+		t.Fatalf("got %d versions, want 20", len(versions))
+	}
+
+	// Newest first, each with its own value or error
+	for i, v := range versions {
+		n := 19 - i
+		if v.Version != ids[n] {
+			t.Errorf("versions[%d] = %s, want %s", i, v.Version, ids[n])
+		}
+		if n == 3 {
+			if !errors.Is(v.ValueErr, broken) || v.Value != "" {
+				t.Errorf("broken version: Value %q, ValueErr %v", v.Value, v.ValueErr)
+			}
+			continue
+		}
+		if v.ValueErr != nil || v.Value != fmt.Sprintf("v%d", n) {
+			t.Errorf("versions[%d]: Value %q, ValueErr %v", i, v.Value, v.ValueErr)
+		}
+	}
+
+	if s.maxFlight > 4 {
+		t.Errorf("%d fetches ran at once, want at most 4", s.maxFlight)
+	}
+}
+
+func TestFetchValuesRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		throttles  int
+		maxRetries *int
+		wantErr    bool
+		wantCalls  int
+	}{
+		{"default retries recover", 2, nil, false, 3},
+		{"retries exhausted", 3, retries(2), true, 3},
+		{"retries off", 1, retries(0), true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScriptedStore()
+			ids := addVersions(t, s, 1)
+			s.throttles[ids[0]] = tt.throttles
+
+			versions, err := s.ListSecretVersions(context.Background(), "db")
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := &FetchOptions{MaxRetries: tt.maxRetries, InitialBackoff: time.Millisecond}
+			if err := FetchValues(context.Background(), s, "db", versions, opts); err != nil {
+				t.Fatalf("FetchValues: %v", err)
+			}
+
+			gotErr := versions[0].ValueErr
+			if tt.wantErr != (gotErr != nil) {
+				t.Errorf("ValueErr = %v, want error: %t", gotErr, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(gotErr, ErrThrottled) {
+				t.Errorf("ValueErr = %v, want ErrThrottled", gotErr)
+			}
+			if s.calls[ids[0]] != tt.wantCalls {
+				t.Errorf("GetSecret called %d times, want %d", s.calls[ids[0]], tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFetchLatest(t *testing.T) {
+	ctx := context.Background()
+	s := newScriptedStore()
+
+	var names []string
+	for i := range 10 {
+		name := fmt.Sprintf("secret-%d", i)
+		names = append(names, name)
+		s.SetSecret(ctx, name, "old", nil)
+		s.SetSecret(ctx, name, "value-"+name, &SecretAttributes{Tags: map[string]string{"n": name}})
+	}
+	off := false
+	if _, err := s.UpdateSecretProperties(ctx, "secret-2", "", SecretAttributes{Enabled: &off}); err != nil {
+		t.Fatal(err)
+	}
+	s.throttles["secret-5"] = 1
+
+	latest, err := FetchLatest(ctx, s, names, &FetchOptions{Concurrency: 3, InitialBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("FetchLatest: %v", err)
+	}
+	if len(latest) != len(names) {
+		t.Fatalf("got %d results, want %d", len(latest), len(names))
+	}
+	for i, v := range latest {
+		if names[i] == "secret-2" {
+			if !errors.Is(v.ValueErr, ErrSecretDisabled) {
+				t.Errorf("disabled secret: ValueErr = %v, want ErrSecretDisabled", v.ValueErr)
+			}
+			continue
+		}
+		if v.ValueErr != nil || v.Value != "value-"+names[i] || v.Tags["n"] != names[i] {
+			t.Errorf("latest[%d] = %q %v %v, want the latest version of %s", i, v.Value, v.Tags, v.ValueErr, names[i])
+		}
+	}
+
+	if s.calls["secret-5"] != 2 {
+		t.Errorf("throttled secret fetched %d times, want 2", s.calls["secret-5"])
+	}
+	if s.maxFlight > 3 {
+		t.Errorf("%d fetches ran at once, want at most 3", s.maxFlight)
+	}
+}
+
+// hintStore throttles the first fetch with a Retry-After hint
+type hintStore struct {
+	*MemoryStore
+	hinted bool
+}
+
+func (s *hintStore) GetSecret(ctx context.Context, name, version string) (*SecretVersion, error) {
+	if !s.hinted {
+		s.hinted = true
+		return nil, &throttledError{retryAfter: 50 * time.Millisecond, err: errors.New("429 Too Many Requests")}
+	}
+	return s.MemoryStore.GetSecret(ctx, name, version)
+}
+
+func TestFetchValuesHonorsRetryAfter(t *testing.T) {
+	s := &hintStore{MemoryStore: NewMemoryStore()}
+	if _, err := s.SetSecret(context.Background(), "db", "value", nil); err != nil {
+		t.Fatal(err)
+	}
+	versions, _ := s.ListSecretVersions(context.Background(), "db")
+
+	start := time.Now()
+	opts := &FetchOptions{InitialBackoff: time.Millisecond}
+	if err := FetchValues(context.Background(), s, "db", versions, opts); err != nil {
+		t.Fatalf("FetchValues: %v", err)
+	}
+	if versions[0].Value != "value" || versions[0].ValueErr != nil {
+		t.Errorf("Value %q, ValueErr %v", versions[0].Value, versions[0].ValueErr)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retried after %v, want at least the 50ms Retry-After", elapsed)
+	}
+}
+
+func TestFetchValuesCanceled(t *testing.T) {
+	s := newScriptedStore()
+	addVersions(t, s, 5)
+	versions, _ := s.ListSecretVersions(context.Background(), "db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := FetchValues(ctx, s, "db", versions, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchValues error = %v, want context.Canceled", err)
+	}
+}
+
+// throttlingTransport answers Key Vault's auth challenge and then throttles
+// every request, counting the authenticated ones
+type throttlingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (tr *throttlingTransport) Do(req *http.Request) (*http.Response, error) {
+	resp := &http.Response{Request: req, Header: http.Header{}, Body: http.NoBody}
+	if req.Header.Get("Authorization") == "" {
+		resp.StatusCode = http.StatusUnauthorized
+		resp.Header.Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`)
+		return resp, nil
+	}
+
+	tr.mu.Lock()
+	tr.requests++
+	tr.mu.Unlock()
+	resp.StatusCode = http.StatusTooManyRequests
+	return resp, nil
+}
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestFetchLeavesThrottlingToThePool(t *testing.T) {
+	tr := &throttlingTransport{}
+	sdk, err := azsecrets.NewClient("https://fake.vault.azure.net", fakeCredential{}, &azsecrets.ClientOptions{
+		ClientOptions: policy.ClientOptions{Transport: tr},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &Client{client: sdk}
+
+	latest, err := FetchLatest(context.Background(), client, []string{"db"}, &FetchOptions{MaxRetries: retries(1), InitialBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("FetchLatest: %v", err)
+	}
+	if !errors.Is(latest[0].ValueErr, ErrThrottled) {
+		t.Errorf("ValueErr = %v, want ErrThrottled", latest[0].ValueErr)
+	}
+	// One request plus one retry by the pool, none by the SDK
+	if tr.requests != 2 {
+		t.Errorf("sent %d requests, want 2", tr.requests)
+	}
+}
@@ -0,0 +1,411 @@
+package keyvault
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a thread-safe in-memory SecretStore. It keeps every
+// version of every secret, so it can stand in for a real vault when
+// exercising the commands offline.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	secrets map[string][]SecretVersion // oldest first
+	deleted map[string]deletedEntry
+
+	// Now returns the timestamp recorded on new versions. It defaults to
+	// time.Now and can be replaced to get deterministic timestamps.
+	Now func() time.Time
+
+	// RecoveryLevel is the vault's deletion recovery level. It defaults to
+	// soft delete without purge protection, "Recoverable+Purgeable".
+	RecoveryLevel string
+}
+
+// deletedEntry keeps the versions of a soft-deleted secret for recovery
+type deletedEntry struct {
+	secret   DeletedSecret
+	versions []SecretVersion
+}
+
+// memoryRetention is how long soft-deleted secrets are kept, matching the
+// Key Vault default of 90 days
+const memoryRetention = 90 * 24 * time.Hour
+
+// NewMemoryStore creates an empty in-memory secret store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		secrets:       make(map[string][]SecretVersion),
+		deleted:       make(map[string]deletedEntry),
+		Now:           time.Now,
+		RecoveryLevel: "Recoverable+Purgeable",
+	}
+}
+
+// AddVersion appends a version to a secret as-is, keeping its timestamps,
+// tags and enabled flag. Missing version IDs and creation dates are filled in
+// and the stored version is returned.
+func (s *MemoryStore) AddVersion(secretName string, version SecretVersion) (*SecretVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addVersionLocked(secretName, version)
+}
+
+// addVersionLocked implements AddVersion. Like Key Vault, it refuses to
+// reuse the name of a soft-deleted secret. The caller must hold s.mu for
+// writing, so the check and the append happen together.
+func (s *MemoryStore) addVersionLocked(secretName string, version SecretVersion) (*SecretVersion, error) {
+	if _, deleted := s.deleted[secretName]; deleted {
+		return nil, fmt.Errorf("%w: secret %s is deleted but not purged: recover or purge it first", ErrConflict, secretName)
+	}
+
+	if version.Version == "" {
+		id, err := newVersionID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate version id: %w", err)
+		}
+		version.Version = id
+	}
+	if version.CreatedOn == nil {
+		now := s.Now().UTC()
+		version.CreatedOn = &now
+	}
+	if version.UpdatedOn == nil {
+		version.UpdatedOn = version.CreatedOn
+	}
+
+	s.secrets[secretName] = append(s.secrets[secretName], copyVersion(version))
+	stored := copyVersion(version)
+	stored.RecoveryLevel = s.RecoveryLevel
+	return &stored, nil
+}
+
+// ListSecrets lists the properties of all secrets, sorted by name
+func (s *MemoryStore) ListSecrets(ctx context.Context) ([]SecretProperties, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets := make([]SecretProperties, 0, len(s.secrets))
+	for name := range s.secrets {
+		versions, err := s.versionsLocked(name)
+		if err != nil {
+			continue
+		}
+		secrets = append(secrets, newSecretProperties(name, versions[0]))
+	}
+	sortSecretsByName(secrets)
+
+	return secrets, nil
+}
+
+// ListSecretVersions lists the properties of all versions of a secret,
+// newest first. Values are left empty, matching Client.
+func (s *MemoryStore) ListSecretVersions(ctx context.Context, secretName string) ([]SecretVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions, err := s.versionsLocked(secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range versions {
+		versions[i].Value = ""
+	}
+	return versions, nil
+}
+
+// GetSecret fetches a single version of a secret including its value.
+// An empty version fetches the latest version.
+func (s *MemoryStore) GetSecret(ctx context.Context, secretName, version string) (*SecretVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions, err := s.versionsLocked(secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	found := -1
+	if version == "" {
+		found = 0
+	}
+	for i := range versions {
+		if versions[i].Version == version {
+			found = i
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrSecretNotFound, secretName, version)
+	}
+
+	// Key Vault refuses to return the value of a disabled version
+	if !versions[found].Enabled {
+		return nil, fmt.Errorf("%w: %s/%s", ErrSecretDisabled, secretName, versions[found].Version)
+	}
+	return &versions[found], nil
+}
+
+// versionsLocked returns copies of all versions of a secret, newest first.
+// The caller must hold s.mu.
+func (s *MemoryStore) versionsLocked(secretName string) ([]SecretVersion, error) {
+	stored := s.secrets[secretName]
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, secretName)
+	}
+
+	// Walk backwards so versions sharing a timestamp keep newest-first order
+	versions := make([]SecretVersion, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		v := copyVersion(stored[i])
+		v.RecoveryLevel = s.RecoveryLevel
+		versions = append(versions, v)
+	}
+	sortVersionsNewestFirst(versions)
+
+	return versions, nil
+}
+
+// SetSecret creates a new version of a secret with the given value and
+// attributes. Versions are enabled unless attrs says otherwise. Like Key
+// Vault, it refuses to reuse the name of a soft-deleted secret.
+func (s *MemoryStore) SetSecret(ctx context.Context, secretName, value string, attrs *SecretAttributes) (*SecretVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	version := SecretVersion{
+		Value:   value,
+		Enabled: true,
+	}
+	if attrs != nil {
+		if attrs.ContentType != nil {
+			version.ContentType = *attrs.ContentType
+		}
+		if attrs.Enabled != nil {
+			version.Enabled = *attrs.Enabled
+		}
+		version.Tags = attrs.Tags
+		version.NotBefore = attrs.NotBefore
+		version.ExpiresOn = attrs.ExpiresOn
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addVersionLocked(secretName, version)
+}
+
+// UpdateSecretProperties changes the attributes of an existing version.
+// Nil fields and nil Tags are left unchanged.
+func (s *MemoryStore) UpdateSecretProperties(ctx context.Context, secretName, version string, attrs SecretAttributes) (*SecretVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if version == "" {
+		versions, err := s.versionsLocked(secretName)
+		if err != nil {
+			return nil, err
+		}
+		version = versions[0].Version
+	}
+
+	stored := s.secrets[secretName]
+	for i := range stored {
+		if stored[i].Version != version {
+			continue
+		}
+
+		v := &stored[i]
+		if attrs.ContentType != nil {
+			v.ContentType = *attrs.ContentType
+		}
+		if attrs.Enabled != nil {
+			v.Enabled = *attrs.Enabled
+		}
+		if attrs.NotBefore != nil {
+			v.NotBefore = copyTime(attrs.NotBefore)
+		}
+		if attrs.ExpiresOn != nil {
+			v.ExpiresOn = copyTime(attrs.ExpiresOn)
+		}
+		if attrs.Tags != nil {
+			v.Tags = copyVersion(SecretVersion{Tags: attrs.Tags}).Tags
+		}
+		now := s.Now().UTC()
+		v.UpdatedOn = &now
+
+		updated := copyVersion(*v)
+		updated.Value = ""
+		updated.RecoveryLevel = s.RecoveryLevel
+		return &updated, nil
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrSecretNotFound, secretName, version)
+}
+
+// DeleteSecret deletes all versions of a secret. Unless RecoveryLevel
+// disables soft delete, they are kept until recovered or purged.
+func (s *MemoryStore) DeleteSecret(ctx context.Context, secretName string) (*DeletedSecret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.versionsLocked(secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now().UTC()
+	purgeOn := now.Add(memoryRetention)
+	deleted := DeletedSecret{
+		Name:               secretName,
+		RecoveryLevel:      s.RecoveryLevel,
+		ContentType:        versions[0].ContentType,
+		DeletedOn:          &now,
+		ScheduledPurgeDate: &purgeOn,
+		Tags:               versions[0].Tags,
+	}
+
+	if Recoverable(s.RecoveryLevel) {
+		deleted.RecoveryID = "deletedsecrets/" + secretName
+		s.deleted[secretName] = deletedEntry{secret: deleted, versions: s.secrets[secretName]}
+	} else {
+		deleted.ScheduledPurgeDate = nil
+	}
+	delete(s.secrets, secretName)
+
+	return copyDeleted(deleted), nil
+}
+
+// ListDeletedSecrets lists the soft-deleted secrets, sorted by name
+func (s *MemoryStore) ListDeletedSecrets(ctx context.Context) ([]DeletedSecret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets := make([]DeletedSecret, 0, len(s.deleted))
+	for _, entry := range s.deleted {
+		secrets = append(secrets, *copyDeleted(entry.secret))
+	}
+	sortDeletedByName(secrets)
+
+	return secrets, nil
+}
+
+// RecoverDeletedSecret restores a soft-deleted secret with all its versions.
+// It fails with ErrConflict rather than replace a live secret of the same
+// name.
+func (s *MemoryStore) RecoverDeletedSecret(ctx context.Context, secretName string) (*SecretVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.deleted[secretName]
+	if !ok {
+		return nil, fmt.Errorf("%w: deleted secret %s", ErrSecretNotFound, secretName)
+	}
+	if len(s.secrets[secretName]) > 0 {
+		return nil, fmt.Errorf("%w: secret %s already exists", ErrConflict, secretName)
+	}
+	delete(s.deleted, secretName)
+	s.secrets[secretName] = entry.versions
+
+	versions, err := s.versionsLocked(secretName)
+	if err != nil {
+		return nil, err
+	}
+	latest := versions[0]
+	latest.Value = ""
+	return &latest, nil
+}
+
+// PurgeDeletedSecret permanently deletes a soft-deleted secret. It fails
+// with ErrForbidden when RecoveryLevel says purge protection is on.
+func (s *MemoryStore) PurgeDeletedSecret(ctx context.Context, secretName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.deleted[secretName]; !ok {
+		return fmt.Errorf("%w: deleted secret %s", ErrSecretNotFound, secretName)
+	}
+	if !Purgeable(s.RecoveryLevel) {
+		return fmt.Errorf("%w: purge protection is enabled", ErrForbidden)
+	}
+	delete(s.deleted, secretName)
+	return nil
+}
+
+// newVersionID generates a random 32 character hex ID like Key Vault does
+func newVersionID() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
+
+// copyVersion returns a copy of a version that shares no memory with the original
+func copyVersion(v SecretVersion) SecretVersion {
+	v.CreatedOn = copyTime(v.CreatedOn)
+	v.UpdatedOn = copyTime(v.UpdatedOn)
+	v.NotBefore = copyTime(v.NotBefore)
+	v.ExpiresOn = copyTime(v.ExpiresOn)
+	if v.Tags != nil {
+		tags := make(map[string]string, len(v.Tags))
+		for key, value := range v.Tags {
+			tags[key] = value
+		}
+		v.Tags = tags
+	}
+	return v
+}
+
+// copyDeleted returns a copy of a deleted secret that shares no memory with the original
+func copyDeleted(d DeletedSecret) *DeletedSecret {
+	d.DeletedOn = copyTime(d.DeletedOn)
+	d.ScheduledPurgeDate = copyTime(d.ScheduledPurgeDate)
+	d.Tags = copyVersion(SecretVersion{Tags: d.Tags}).Tags
+	return &d
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,496 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestStore returns a MemoryStore whose clock advances one minute per
+// call, starting at start
+func newTestStore(start time.Time) *MemoryStore {
+	s := NewMemoryStore()
+	now := start
+	s.Now = func() time.Time {
+		t := now
+		now = now.Add(time.Minute)
+		return t
+	}
+	return s
+}
+
+func TestMemoryStoreVersionsNewestFirst(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestStore(start)
+
+	var ids []string
+	for _, value := range []string{"one", "two", "three"} {
+		v, err := s.SetSecret(ctx, "db", value, nil)
+		if err != nil {
+			t.Fatalf("SetSecret(%q): %v", value, err)
+		}
+		ids = append(ids, v.Version)
+	}
+
+	versions, err := s.ListSecretVersions(ctx, "db")
+	if err != nil {
+		t.Fatalf("ListSecretVersions: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("got %d versions, want 3", len(versions))
+	}
+	for i, want := range []string{ids[2], ids[1], ids[0]} {
+		if versions[i].Version != want {
+			t.Errorf("versions[%d] = %s, want %s", i, versions[i].Version, want)
+		}
+		if versions[i].Value != "" {
+			t.Errorf("versions[%d].Value = %q, want it left empty", i, versions[i].Value)
+		}
+	}
+
+	latest, err := s.GetSecret(ctx, "db", "")
+	if err != nil {
+		t.Fatalf("GetSecret latest: %v", err)
+	}
+	if latest.Value != "three" || latest.Version != ids[2] {
+		t.Errorf("latest = %s %q, want %s %q", latest.Version, latest.Value, ids[2], "three")
+	}
+
+	first, err := s.GetSecret(ctx, "db", ids[0])
+	if err != nil {
+		t.Fatalf("GetSecret first: %v", err)
+	}
+	if first.Value != "one" {
+		t.Errorf("first.Value = %q, want %q", first.Value, "one")
+	}
+}
+
+func TestMemoryStoreSameTimestampKeepsInsertionOrder(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	fixed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.Now = func() time.Time { return fixed }
+
+	older, _ := s.SetSecret(ctx, "db", "older", nil)
+	newer, _ := s.SetSecret(ctx, "db", "newer", nil)
+
+	versions, err := s.ListSecretVersions(ctx, "db")
+	if err != nil {
+		t.Fatalf("ListSecretVersions: %v", err)
+	}
+	if versions[0].Version != newer.Version || versions[1].Version != older.Version {
+		t.Errorf("got order %s, %s; want newest first", versions[0].Version, versions[1].Version)
+	}
+}
+
+func TestMemoryStoreTimestamps(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestStore(start)
+
+	created, err := s.SetSecret(ctx, "db", "v", nil)
+	if err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	if !created.CreatedOn.Equal(start) || !created.UpdatedOn.Equal(start) {
+		t.Errorf("CreatedOn/UpdatedOn = %v/%v, want %v", created.CreatedOn, created.UpdatedOn, start)
+	}
+
+	contentType := "text/plain"
+	updated, err := s.UpdateSecretProperties(ctx, "db", created.Version, SecretAttributes{ContentType: &contentType})
+	if err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+	if !updated.CreatedOn.Equal(start) {
+		t.Errorf("CreatedOn changed to %v", updated.CreatedOn)
+	}
+	if want := start.Add(time.Minute); !updated.UpdatedOn.Equal(want) {
+		t.Errorf("UpdatedOn = %v, want %v", updated.UpdatedOn, want)
+	}
+	if updated.Value != "" {
+		t.Errorf("UpdateSecretProperties returned value %q", updated.Value)
+	}
+
+	// AddVersion keeps the timestamps it is given
+	past := start.Add(-24 * time.Hour)
+	added, err := s.AddVersion("old", SecretVersion{Value: "x", Enabled: true, CreatedOn: &past})
+	if err != nil {
+		t.Fatalf("AddVersion: %v", err)
+	}
+	if !added.CreatedOn.Equal(past) || !added.UpdatedOn.Equal(past) {
+		t.Errorf("AddVersion timestamps = %v/%v, want %v", added.CreatedOn, added.UpdatedOn, past)
+	}
+}
+
+func TestMemoryStoreCopiesTags(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	tags := map[string]string{"env": "prod"}
+	created, err := s.SetSecret(ctx, "db", "v", &SecretAttributes{Tags: tags})
+	if err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+
+	// Neither the caller's map nor returned maps may alias the stored tags
+	tags["env"] = "changed by caller"
+	created.Tags["env"] = "changed via result"
+
+	listed, _ := s.ListSecretVersions(ctx, "db")
+	listed[0].Tags["env"] = "changed via list"
+
+	got, err := s.GetSecret(ctx, "db", "")
+	if err != nil {
+		t.Fatalf("GetSecret: %v", err)
+	}
+	if got.Tags["env"] != "prod" {
+		t.Errorf("stored tag env = %q, want %q", got.Tags["env"], "prod")
+	}
+
+	// Nil tags leave the stored tags alone; a non-nil map replaces them
+	if _, err := s.UpdateSecretProperties(ctx, "db", "", SecretAttributes{}); err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+	got, _ = s.GetSecret(ctx, "db", "")
+	if got.Tags["env"] != "prod" {
+		t.Errorf("nil Tags changed the tags to %v", got.Tags)
+	}
+
+	replacement := map[string]string{"team": "payments"}
+	if _, err := s.UpdateSecretProperties(ctx, "db", "", SecretAttributes{Tags: replacement}); err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+	replacement["team"] = "changed by caller"
+	got, _ = s.GetSecret(ctx, "db", "")
+	if len(got.Tags) != 1 || got.Tags["team"] != "payments" {
+		t.Errorf("tags = %v, want map[team:payments]", got.Tags)
+	}
+}
+
+func TestMemoryStoreEnabled(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	enabled, _ := s.SetSecret(ctx, "db", "old", nil)
+	disabledFlag := false
+	disabled, err := s.SetSecret(ctx, "db", "new", &SecretAttributes{Enabled: &disabledFlag})
+	if err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	if !enabled.Enabled || disabled.Enabled {
+		t.Fatalf("Enabled = %t/%t, want true/false", enabled.Enabled, disabled.Enabled)
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		wantErr error
+	}{
+		{"latest disabled", "", ErrSecretDisabled},
+		{"explicit disabled", disabled.Version, ErrSecretDisabled},
+		{"enabled", enabled.Version, nil},
+		{"missing version", "nope", ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetSecret(ctx, "db", tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetSecret(%q) error = %v, want %v", tt.version, err, tt.wantErr)
+			}
+		})
+	}
+
+	secrets, err := s.ListSecrets(ctx)
+	if err != nil {
+		t.Fatalf("ListSecrets: %v", err)
+	}
+	if len(secrets) != 1 || secrets[0].Enabled {
+		t.Errorf("ListSecrets = %+v, want db reported disabled like its latest version", secrets)
+	}
+
+	on := true
+	if _, err := s.UpdateSecretProperties(ctx, "db", disabled.Version, SecretAttributes{Enabled: &on}); err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+	if got, err := s.GetSecret(ctx, "db", ""); err != nil || got.Value != "new" {
+		t.Errorf("after enabling: GetSecret = %v, %v", got, err)
+	}
+}
+
+func TestMemoryStoreNotFound(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	if _, err := s.ListSecretVersions(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("ListSecretVersions error = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := s.GetSecret(ctx, "missing", ""); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecret error = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := s.UpdateSecretProperties(ctx, "missing", "", SecretAttributes{}); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("UpdateSecretProperties error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestMemoryStoreListSecretsSorted(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	for _, name := range []string{"zeta", "alpha", "mid"} {
+		if _, err := s.SetSecret(ctx, name, "v", nil); err != nil {
+			t.Fatalf("SetSecret(%s): %v", name, err)
+		}
+	}
+
+	secrets, err := s.ListSecrets(ctx)
+	if err != nil {
+		t.Fatalf("ListSecrets: %v", err)
+	}
+	var names []string
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+	if fmt.Sprint(names) != "[alpha mid zeta]" {
+		t.Errorf("names = %v, want sorted", names)
+	}
+}
+
+func TestMemoryStoreCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := NewMemoryStore()
+	if _, err := s.SetSecret(ctx, "db", "v", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("SetSecret error = %v, want context.Canceled", err)
+	}
+	if _, err := s.ListSecrets(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListSecrets error = %v, want context.Canceled", err)
+	}
+}
+
+func TestMemoryStoreConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	const writers, perWriter = 8, 25
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if _, err := s.SetSecret(ctx, "db", fmt.Sprintf("w%d-%d", w, i), &SecretAttributes{Tags: map[string]string{"w": "x"}}); err != nil {
+					t.Errorf("SetSecret: %v", err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				s.ListSecretVersions(ctx, "db")
+				s.GetSecret(ctx, "db", "")
+				s.ListSecrets(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+
+	versions, err := s.ListSecretVersions(ctx, "db")
+	if err != nil {
+		t.Fatalf("ListSecretVersions: %v", err)
+	}
+	if len(versions) != writers*perWriter {
+		t.Errorf("got %d versions, want %d", len(versions), writers*perWriter)
+	}
+
+	seen := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		if seen[v.Version] {
+			t.Errorf("duplicate version ID %s", v.Version)
+		}
+		seen[v.Version] = true
+	}
+}
+
+func TestMemoryStoreDeleteLifecycle(t *testing.T) {
+	type step struct {
+		op      string // set, delete, recover or purge
+		wantErr error
+	}
+	tests := []struct {
+		name          string
+		recoveryLevel string
+		steps         []step
+		wantValue     string // latest value afterwards, "" if the secret is gone
+		wantDeleted   bool
+	}{
+		{
+			name:          "delete keeps the secret for recovery",
+			recoveryLevel: "Recoverable+Purgeable",
+			steps:         []step{{"delete", nil}},
+			wantDeleted:   true,
+		},
+		{
+			name:          "set refused while deleted",
+			recoveryLevel: "Recoverable+Purgeable",
+			steps:         []step{{"delete", nil}, {"set", ErrConflict}},
+			wantDeleted:   true,
+		},
+		{
+			name:          "recover restores all versions",
+			recoveryLevel: "Recoverable+Purgeable",
+			steps:         []step{{"delete", nil}, {"recover", nil}, {"recover", ErrSecretNotFound}},
+			wantValue:     "v2",
+		},
+		{
+			name:          "set allowed again after recovery",
+			recoveryLevel: "Recoverable+Purgeable",
+			steps:         []step{{"delete", nil}, {"recover", nil}, {"set", nil}},
+			wantValue:     "v3",
+		},
+		{
+			name:          "purge frees the name",
+			recoveryLevel: "Recoverable+Purgeable",
+			steps:         []step{{"delete", nil}, {"purge", nil}, {"recover", ErrSecretNotFound}, {"set", nil}},
+			wantValue:     "v3",
+		},
+		{
+			name:          "purge of a live secret",
+			recoveryLevel: "Recoverable+Purgeable",
+			steps:         []step{{"purge", ErrSecretNotFound}},
+			wantValue:     "v2",
+		},
+		{
+			name:          "purge protection",
+			recoveryLevel: "Recoverable",
+			steps:         []step{{"delete", nil}, {"purge", ErrForbidden}, {"recover", nil}},
+			wantValue:     "v2",
+		},
+		{
+			name:          "without soft delete",
+			recoveryLevel: "Purgeable",
+			steps:         []step{{"delete", nil}, {"recover", ErrSecretNotFound}, {"set", nil}},
+			wantValue:     "v3",
+		},
+		{
+			name:          "delete twice",
+			recoveryLevel: "Recoverable+Purgeable",
+			steps:         []step{{"delete", nil}, {"delete", ErrSecretNotFound}},
+			wantDeleted:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestStore(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			s.RecoveryLevel = tt.recoveryLevel
+			for _, value := range []string{"v1", "v2"} {
+				if _, err := s.SetSecret(ctx, "db", value, nil); err != nil {
+					t.Fatalf("SetSecret: %v", err)
+				}
+			}
+
+			for i, st := range tt.steps {
+				var err error
+				switch st.op {
+				case "set":
+					_, err = s.SetSecret(ctx, "db", "v3", nil)
+				case "delete":
+					_, err = s.DeleteSecret(ctx, "db")
+				case "recover":
+					_, err = s.RecoverDeletedSecret(ctx, "db")
+				case "purge":
+					err = s.PurgeDeletedSecret(ctx, "db")
+				}
+				if !errors.Is(err, st.wantErr) || (st.wantErr == nil) != (err == nil) {
+					t.Fatalf("step %d (%s) error = %v, want %v", i, st.op, err, st.wantErr)
+				}
+			}
+
+			latest, err := s.GetSecret(ctx, "db", "")
+			switch {
+			case tt.wantValue == "" && !errors.Is(err, ErrSecretNotFound):
+				t.Errorf("GetSecret = %v, %v; want ErrSecretNotFound", latest, err)
+			case tt.wantValue != "" && (err != nil || latest.Value != tt.wantValue):
+				t.Errorf("GetSecret = %v, %v; want value %q", latest, err, tt.wantValue)
+			}
+			if tt.wantValue == "v2" {
+				if versions, _ := s.ListSecretVersions(ctx, "db"); len(versions) != 2 {
+					t.Errorf("got %d versions after recovery, want 2", len(versions))
+				}
+			}
+
+			deleted, err := s.ListDeletedSecrets(ctx)
+			if err != nil {
+				t.Fatalf("ListDeletedSecrets: %v", err)
+			}
+			if got := len(deleted) == 1 && deleted[0].Name == "db"; got != tt.wantDeleted {
+				t.Errorf("ListDeletedSecrets = %+v, want db deleted: %t", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRecoverOverLiveSecret(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	s.SetSecret(ctx, "db", "old", nil)
+	if _, err := s.DeleteSecret(ctx, "db"); err != nil {
+		t.Fatalf("DeleteSecret: %v", err)
+	}
+
+	// SetSecret and AddVersion refuse to write while the secret is deleted,
+	// so put the live secret in place directly
+	s.mu.Lock()
+	s.secrets["db"] = []SecretVersion{{Version: "live", Value: "live", Enabled: true}}
+	s.mu.Unlock()
+
+	if _, err := s.RecoverDeletedSecret(ctx, "db"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("RecoverDeletedSecret error = %v, want ErrConflict", err)
+	}
+	if got, err := s.GetSecret(ctx, "db", ""); err != nil || got.Value != "live" {
+		t.Errorf("GetSecret = %v, %v; want the live secret untouched", got, err)
+	}
+	if deleted, _ := s.ListDeletedSecrets(ctx); len(deleted) != 1 {
+		t.Errorf("ListDeletedSecrets = %+v, want the deleted secret kept", deleted)
+	}
+}
+
+func TestMemoryStoreConcurrentSetAndDelete(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	s.SetSecret(ctx, "db", "v", nil)
+
+	// Writers racing a delete must either land before it or be refused;
+	// nothing may be written under the name while it is deleted
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				if _, err := s.SetSecret(ctx, "db", "v", nil); err != nil && !errors.Is(err, ErrConflict) {
+					t.Errorf("SetSecret: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := s.DeleteSecret(ctx, "db"); err != nil {
+			t.Errorf("DeleteSecret: %v", err)
+		}
+	}()
+	wg.Wait()
+
+	if _, err := s.GetSecret(ctx, "db", ""); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecret after delete = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := s.RecoverDeletedSecret(ctx, "db"); err != nil {
+		t.Errorf("RecoverDeletedSecret: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SecretStore is the set of secret operations the commands depend on.
+// Client is the Azure Key Vault implementation; MemoryStore is an
+// in-memory implementation for offline use and tests.
+type SecretStore interface {
+	// ListSecrets lists the properties of all secrets in the vault, sorted by name
+	ListSecrets(ctx context.Context) ([]SecretProperties, error)
+
+	// ListSecretVersions lists the properties of all versions of a secret,
+	// newest first. Values are left empty; use GetSecret to fetch them.
+	ListSecretVersions(ctx context.Context, secretName string) ([]SecretVersion, error)
+
+	// GetSecret fetches a single version of a secret including its value.
+	// An empty version fetches the latest version.
+	GetSecret(ctx context.Context, secretName, version string) (*SecretVersion, error)
+
+	// SetSecret creates a new version of a secret with the given value and
+	// attributes. attrs may be nil.
+	SetSecret(ctx context.Context, secretName, value string, attrs *SecretAttributes) (*SecretVersion, error)
+
+	// UpdateSecretProperties changes the attributes of an existing version
+	// without creating a new one. Nil fields and nil Tags are left unchanged.
+	// An empty version updates the latest version.
+	UpdateSecretProperties(ctx context.Context, secretName, version string, attrs SecretAttributes) (*SecretVersion, error)
+
+	// DeleteSecret deletes all versions of a secret. With soft delete the
+	// secret can be recovered until its scheduled purge date.
+	DeleteSecret(ctx context.Context, secretName string) (*DeletedSecret, error)
+
+	// ListDeletedSecrets lists the soft-deleted secrets, sorted by name
+	ListDeletedSecrets(ctx context.Context) ([]DeletedSecret, error)
+
+	// RecoverDeletedSecret restores a soft-deleted secret and returns the
+	// properties of its latest version
+	RecoverDeletedSecret(ctx context.Context, secretName string) (*SecretVersion, error)
+
+	// PurgeDeletedSecret permanently deletes a soft-deleted secret
+	PurgeDeletedSecret(ctx context.Context, secretName string) error
+}
+
+var (
+	_ SecretStore = (*Client)(nil)
+	_ SecretStore = (*MemoryStore)(nil)
+)
+
+// sortVersionsNewestFirst sorts versions by creation date (newest first).
+// Versions without a creation date are placed last.
+func sortVersionsNewestFirst(versions []SecretVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].CreatedOn == nil {
+			return false
+		}
+		if versions[j].CreatedOn == nil {
+			return true
+		}
+		return versions[i].CreatedOn.After(*versions[j].CreatedOn)
+	})
+}
+
+// sortSecretsByName sorts secrets alphabetically by name
+func sortSecretsByName(secrets []SecretProperties) {
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+}
+
+// sortDeletedByName sorts deleted secrets alphabetically by name
+func sortDeletedByName(secrets []DeletedSecret) {
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+}
+
+// newSecretProperties describes a secret by the attributes of its latest version
+func newSecretProperties(name string, latest SecretVersion) SecretProperties {
+	return SecretProperties{
+		Name:        name,
+		Enabled:     latest.Enabled,
+		ContentType: latest.ContentType,
+		CreatedOn:   latest.CreatedOn,
+		UpdatedOn:   latest.UpdatedOn,
+		ExpiresOn:   latest.ExpiresOn,
+		Tags:        latest.Tags,
+	}
+}
+
+// ShortVersion abbreviates a version ID to its first 8 characters for display
+func ShortVersion(version string) string {
+	if len(version) > 8 {
+		return version[:8]
+	}
+	return version
+}
+
+// LatestVersion returns the latest version of a secret including its value.
+// A disabled latest version still has readable metadata, so it is returned
+// without a value and with ValueErr set to ErrSecretDisabled.
+func LatestVersion(ctx context.Context, store SecretStore, secretName string) (*SecretVersion, error) {
+	versions, err := store.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s has no versions", ErrSecretNotFound, secretName)
+	}
+
+	latest := versions[0]
+	secret, err := store.GetSecret(ctx, secretName, latest.Version)
+	if errors.Is(err, ErrSecretDisabled) {
+		latest.ValueErr = err
+		return &latest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// FindVersion finds a version by its full ID or a unique prefix of it
+func FindVersion(versions []SecretVersion, id string) (SecretVersion, error) {
+	if id == "" {
+		return SecretVersion{}, fmt.Errorf("%w: empty version", ErrSecretNotFound)
+	}
+
+	var matches []SecretVersion
+	for _, v := range versions {
+		if v.Version == id {
+			return v, nil
+		}
+		if strings.HasPrefix(v.Version, id) {
+			matches = append(matches, v)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return SecretVersion{}, fmt.Errorf("%w: version %s", ErrSecretNotFound, id)
+	case 1:
+		return matches[0], nil
+	default:
+		return SecretVersion{}, fmt.Errorf("version prefix %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
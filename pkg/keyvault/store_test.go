@@ -0,0 +1,47 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLatestVersion(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.SetSecret(ctx, "db", "old", nil)
+	tags := map[string]string{"env": "prod"}
+	newest, _ := s.SetSecret(ctx, "db", "new", &SecretAttributes{Tags: tags})
+
+	got, err := LatestVersion(ctx, s, "db")
+	if err != nil {
+		t.Fatalf("LatestVersion: %v", err)
+	}
+	if got.Version != newest.Version || got.Value != "new" || got.ValueErr != nil {
+		t.Errorf("latest = %s %q %v, want %s %q", got.Version, got.Value, got.ValueErr, newest.Version, "new")
+	}
+
+	// A disabled latest version keeps its metadata but has no value
+	off := false
+	if _, err := s.UpdateSecretProperties(ctx, "db", newest.Version, SecretAttributes{Enabled: &off}); err != nil {
+		t.Fatalf("UpdateSecretProperties: %v", err)
+	}
+	got, err = LatestVersion(ctx, s, "db")
+	if err != nil {
+		t.Fatalf("LatestVersion with disabled latest: %v", err)
+	}
+	if got.Version != newest.Version || got.Value != "" || got.Enabled {
+		t.Errorf("latest = %s %q enabled=%t, want %s without a value", got.Version, got.Value, got.Enabled, newest.Version)
+	}
+	if !errors.Is(got.ValueErr, ErrSecretDisabled) {
+		t.Errorf("ValueErr = %v, want ErrSecretDisabled", got.ValueErr)
+	}
+	if got.Tags["env"] != "prod" {
+		t.Errorf("tags = %v, want the disabled version's tags", got.Tags)
+	}
+
+	if _, err := LatestVersion(ctx, s, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("missing secret: error = %v, want ErrSecretNotFound", err)
+	}
+}